@@ -0,0 +1,134 @@
+package main
+
+import (
+	"html"
+	"strings"
+)
+
+type AtomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+type AtomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+type AtomPerson struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email"`
+}
+
+type AtomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+type AtomEntry struct {
+	Title     AtomText       `xml:"title"`
+	Link      []AtomLink     `xml:"link"`
+	ID        string         `xml:"id"`
+	Published string         `xml:"published"`
+	Updated   string         `xml:"updated"`
+	Summary   AtomText       `xml:"summary"`
+	Content   AtomText       `xml:"content"`
+	Author    []AtomPerson   `xml:"author"`
+	Category  []AtomCategory `xml:"category"`
+}
+
+type AtomFeed struct {
+	Lang      string       `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Title     AtomText     `xml:"title"`
+	Link      []AtomLink   `xml:"link"`
+	Subtitle  AtomText     `xml:"subtitle"`
+	Icon      string       `xml:"icon"`
+	Logo      string       `xml:"logo"`
+	Generator string       `xml:"generator"`
+	Author    []AtomPerson `xml:"author"`
+	Entry     []AtomEntry  `xml:"entry"`
+}
+
+// Return text construct content, keeping markup for xhtml content
+func (t AtomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+// Return href of alternate link (rel is alternate or omitted), preferring HTML
+func atomAlternateLink(links []AtomLink) string {
+	var href string
+	for _, link := range links {
+		if link.Rel != "" && link.Rel != "alternate" {
+			continue
+		}
+		if link.Type == "" || link.Type == "text/html" {
+			return link.Href
+		}
+		if href == "" {
+			href = link.Href
+		}
+	}
+	return href
+}
+
+// Convert Atom feed into RSSFeed so entries share the RSS post pipeline
+func (f *AtomFeed) rssFeed() *RSSFeed {
+	var (
+		rssFeed RSSFeed
+		rssItem RSSItem
+	)
+	rssFeed.Channel.Title = html.UnescapeString(f.Title.String())
+	rssFeed.Channel.Link = atomAlternateLink(f.Link)
+	rssFeed.Channel.Description = html.UnescapeString(f.Subtitle.String())
+	rssFeed.Channel.Language = f.Lang
+	rssFeed.Channel.Generator = strings.TrimSpace(f.Generator)
+	rssFeed.Channel.Image.URL = strings.TrimSpace(f.Logo)
+	if rssFeed.Channel.Image.URL == "" {
+		rssFeed.Channel.Image.URL = strings.TrimSpace(f.Icon)
+	}
+	for _, entry := range f.Entry {
+		rssItem = RSSItem{}
+		rssItem.Title = html.UnescapeString(entry.Title.String())
+		rssItem.Link = atomAlternateLink(entry.Link)
+		rssItem.GUID = strings.TrimSpace(entry.ID)
+		// summary is preferred, with content used when no summary is provided
+		rssItem.Description = entry.Summary.String()
+		if rssItem.Description == "" {
+			rssItem.Description = entry.Content.String()
+		}
+		rssItem.Content = entry.Content.String()
+		// entries without authors inherit authors of the feed
+		authors := entry.Author
+		if len(authors) == 0 {
+			authors = f.Author
+		}
+		for _, author := range authors {
+			rssItem.Author = append(rssItem.Author, author.Name)
+		}
+		for _, category := range entry.Category {
+			if category.Label != "" {
+				rssItem.Category = append(rssItem.Category, category.Label)
+			} else {
+				rssItem.Category = append(rssItem.Category, category.Term)
+			}
+		}
+		for _, link := range entry.Link {
+			if link.Rel == "enclosure" {
+				rssItem.Enclosure = append(rssItem.Enclosure, RSSEnclosure{URL: link.Href, Type: link.Type, Length: link.Length})
+			}
+		}
+		// published is optional in Atom, updated is required
+		rssItem.PubDate = entry.Published
+		if rssItem.PubDate == "" {
+			rssItem.PubDate = entry.Updated
+		}
+		rssFeed.Channel.Item = append(rssFeed.Channel.Item, rssItem)
+	}
+	return &rssFeed
+}
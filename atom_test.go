@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestAtomAlternateLink(t *testing.T) {
+	tests := []struct {
+		name  string
+		links []AtomLink
+		want  string
+	}{
+		{"none", nil, ""},
+		{"rel omitted", []AtomLink{{Href: "https://example.com/a"}}, "https://example.com/a"},
+		{"self skipped", []AtomLink{{Href: "https://example.com/feed", Rel: "self"}, {Href: "https://example.com/a", Rel: "alternate"}}, "https://example.com/a"},
+		{"html preferred", []AtomLink{{Href: "https://example.com/a.json", Rel: "alternate", Type: "application/json"}, {Href: "https://example.com/a", Rel: "alternate", Type: "text/html"}}, "https://example.com/a"},
+		{"other type used without html", []AtomLink{{Href: "https://example.com/a.json", Rel: "alternate", Type: "application/json"}}, "https://example.com/a.json"},
+		{"only enclosure", []AtomLink{{Href: "https://example.com/a.mp3", Rel: "enclosure"}}, ""},
+	}
+	for _, tt := range tests {
+		got := atomAlternateLink(tt.links)
+		if got != tt.want {
+			t.Errorf("%s: atomAlternateLink = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAtomFeedRSSFeed(t *testing.T) {
+	const feed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="en">
+<title>Example &amp;amp; Co</title>
+<link rel="self" href="https://example.com/feed.atom"/>
+<link href="https://example.com/"/>
+<author><name>Feed Author</name></author>
+<entry>
+<title>Summary and content</title>
+<id>urn:1</id>
+<link rel="alternate" type="text/html" href="https://example.com/1"/>
+<link rel="enclosure" type="audio/mpeg" length="123" href="https://example.com/1.mp3"/>
+<published>2024-03-01T10:00:00Z</published>
+<updated>2024-03-02T10:00:00Z</updated>
+<summary>short</summary>
+<content type="html">&lt;p&gt;long&lt;/p&gt;</content>
+<author><name>Entry Author</name></author>
+<category term="go" label="Go"/>
+<category term="rss"/>
+</entry>
+<entry>
+<title>Content only</title>
+<id>urn:2</id>
+<link href="https://example.com/2"/>
+<updated>2024-03-03T10:00:00Z</updated>
+<content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>body</p></div></content>
+</entry>
+</feed>`
+	var atomFeed AtomFeed
+	err := xml.Unmarshal([]byte(feed), &atomFeed)
+	if err != nil {
+		t.Fatalf("xml.Unmarshal error: %v", err)
+	}
+	rssFeed := atomFeed.rssFeed()
+	if rssFeed.Channel.Title != "Example & Co" || rssFeed.Channel.Link != "https://example.com/" || rssFeed.Channel.Language != "en" {
+		t.Errorf("channel = %q, %q, %q", rssFeed.Channel.Title, rssFeed.Channel.Link, rssFeed.Channel.Language)
+	}
+	if len(rssFeed.Channel.Item) != 2 {
+		t.Fatalf("got %d items, want 2", len(rssFeed.Channel.Item))
+	}
+	tests := []struct {
+		field string
+		got   string
+		want  string
+	}{
+		{"link", rssFeed.Channel.Item[0].Link, "https://example.com/1"},
+		{"guid", rssFeed.Channel.Item[0].GUID, "urn:1"},
+		{"summary as description", rssFeed.Channel.Item[0].Description, "short"},
+		{"content", rssFeed.Channel.Item[0].Content, "<p>long</p>"},
+		{"published date", rssFeed.Channel.Item[0].PubDate, "2024-03-01T10:00:00Z"},
+		{"entry author", rssFeed.Channel.Item[0].Author[0], "Entry Author"},
+		{"category label", rssFeed.Channel.Item[0].Category[0], "Go"},
+		{"category term", rssFeed.Channel.Item[0].Category[1], "rss"},
+		{"enclosure", rssFeed.Channel.Item[0].Enclosure[0].URL, "https://example.com/1.mp3"},
+		{"link without rel", rssFeed.Channel.Item[1].Link, "https://example.com/2"},
+		{"content as description", rssFeed.Channel.Item[1].Description, `<div xmlns="http://www.w3.org/1999/xhtml"><p>body</p></div>`},
+		{"updated date", rssFeed.Channel.Item[1].PubDate, "2024-03-03T10:00:00Z"},
+		{"feed author", rssFeed.Channel.Item[1].Author[0], "Feed Author"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.field, tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dragonicorn/gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// RSS author is an email address, usually followed by "(Name)"
+	rssAuthorParens = regexp.MustCompile(`^\S+@\S+\s*\((.+)\)$`)
+	rssAuthorAngles = regexp.MustCompile(`^(.+?)\s*<\S+@\S+>$`)
+)
+
+// Return name from RSS author, e.g. "jane@example.com (Jane Doe)"
+func authorName(author string) string {
+	author = strings.Join(strings.Fields(author), " ")
+	if m := rssAuthorParens.FindStringSubmatch(author); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	if m := rssAuthorAngles.FindStringSubmatch(author); m != nil {
+		return strings.Trim(m[1], " \"")
+	}
+	return author
+}
+
+// Return names without blanks or case-insensitive duplicates
+func uniqueNames(lists ...[]string) []string {
+	var (
+		names []string
+		seen  map[string]bool = make(map[string]bool)
+	)
+	for _, list := range lists {
+		for _, name := range list {
+			name = strings.Join(strings.Fields(name), " ")
+			if name == "" || seen[strings.ToLower(name)] {
+				continue
+			}
+			seen[strings.ToLower(name)] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Return authors of item from author and dc:creator, or itunes:author if neither
+func (rssItem RSSItem) authors() []string {
+	var authors []string
+	for _, author := range rssItem.Author {
+		authors = append(authors, authorName(author))
+	}
+	authors = uniqueNames(authors, rssItem.DCCreator)
+	if len(authors) == 0 {
+		authors = uniqueNames([]string{rssItem.ITunesAuthor})
+	}
+	return authors
+}
+
+// Return categories of item from category and dc:subject
+func (rssItem RSSItem) categories() []string {
+	return uniqueNames(rssItem.Category, rssItem.DCSubject)
+}
+
+// Create authors and categories of items in database and link them to their
+// posts, with one query for each of authors, categories and their links
+func createAuthorsAndCategories(ctx context.Context, s *state, postIDs []uuid.UUID, rssItems []RSSItem) error {
+	var (
+		dbAuthorParams   database.CreateAuthorsParams
+		dbPAParams       database.CreatePostAuthorsParams
+		dbCategoryParams database.CreateCategoriesParams
+		dbPCParams       database.CreatePostCategoriesParams
+		err              error
+	)
+	dbAuthorParams.CreatedAt = time.Now()
+	dbPAParams.CreatedAt = dbAuthorParams.CreatedAt
+	dbCategoryParams.CreatedAt = dbAuthorParams.CreatedAt
+	dbPCParams.CreatedAt = dbAuthorParams.CreatedAt
+	for i, rssItem := range rssItems {
+		for _, name := range rssItem.authors() {
+			dbAuthorParams.Ids = append(dbAuthorParams.Ids, uuid.New())
+			dbAuthorParams.Names = append(dbAuthorParams.Names, name)
+			dbPAParams.Ids = append(dbPAParams.Ids, uuid.New())
+			dbPAParams.PostIds = append(dbPAParams.PostIds, postIDs[i])
+			dbPAParams.Names = append(dbPAParams.Names, name)
+		}
+		for _, name := range rssItem.categories() {
+			dbCategoryParams.Ids = append(dbCategoryParams.Ids, uuid.New())
+			dbCategoryParams.Names = append(dbCategoryParams.Names, name)
+			dbPCParams.Ids = append(dbPCParams.Ids, uuid.New())
+			dbPCParams.PostIds = append(dbPCParams.PostIds, postIDs[i])
+			dbPCParams.Names = append(dbPCParams.Names, name)
+		}
+	}
+	if len(dbAuthorParams.Ids) > 0 {
+		err = s.db.CreateAuthors(ctx, dbAuthorParams)
+		if err != nil {
+			return err
+		}
+		err = s.db.CreatePostAuthors(ctx, dbPAParams)
+		if err != nil {
+			return err
+		}
+	}
+	if len(dbCategoryParams.Ids) > 0 {
+		err = s.db.CreateCategories(ctx, dbCategoryParams)
+		if err != nil {
+			return err
+		}
+		err = s.db.CreatePostCategories(ctx, dbPCParams)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Print authors and categories of post from database
+func printAuthorsAndCategories(ctx context.Context, s *state, postID uuid.UUID) error {
+	var (
+		dbAuthors    []database.Author
+		dbCategories []database.Category
+		names        []string
+		err          error
+	)
+	dbAuthors, err = s.db.GetAuthorsForPost(ctx, postID)
+	if err != nil {
+		return err
+	}
+	for _, author := range dbAuthors {
+		names = append(names, author.Name)
+	}
+	if len(names) > 0 {
+		fmt.Printf("\tAuthors = %s\n", strings.Join(names, ", "))
+	}
+	dbCategories, err = s.db.GetCategoriesForPost(ctx, postID)
+	if err != nil {
+		return err
+	}
+	names = nil
+	for _, category := range dbCategories {
+		names = append(names, category.Name)
+	}
+	if len(names) > 0 {
+		fmt.Printf("\tCategories = %s\n", strings.Join(names, ", "))
+	}
+	return nil
+}
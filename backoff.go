@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dragonicorn/gator/internal/database"
+)
+
+// Delay before retrying a feed after its first failed fetch, doubled after
+// each further consecutive failure up to the maximum
+const (
+	feedRetryDelay    = time.Minute
+	feedMaxRetryDelay = 24 * time.Hour
+)
+
+// Return delay before next fetch of feed after consecutive failures
+func retryDelay(failures int32) time.Duration {
+	var delay time.Duration = feedRetryDelay
+	for i := int32(1); i < failures && delay < feedMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, feedMaxRetryDelay)
+}
+
+// Return local time given by Retry-After header (e.g. of 429 or 503
+// response), in seconds or as an HTTP date
+func retryAfter(header http.Header, now time.Time) (time.Time, bool) {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.Atoi(value)
+	if err == nil && seconds >= 0 {
+		return now.Add(time.Duration(seconds) * time.Second), true
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	// stored in a TIMESTAMP column as local wall clock time, like other times
+	return t.Local(), true
+}
+
+// Record failed fetch of feed, backing off exponentially (or for longer if
+// the response asked to retry later) and disabling the feed once the
+// configured number of consecutive failures is reached
+func recordFeedFailure(ctx context.Context, s *state, dbFeed database.Feed, fetchErr error, header http.Header) error {
+	var (
+		dbParams database.RecordFeedFailureParams
+		limit    int = s.config.FeedFailureLimit()
+		err      error
+	)
+	dbParams.ID = dbFeed.ID
+	dbParams.UpdatedAt = time.Now()
+	dbParams.ConsecutiveFailures = dbFeed.ConsecutiveFailures + 1
+	dbParams.LastError = nullString(fetchErr.Error())
+	dbParams.NextFetchAt = sql.NullTime{Time: dbParams.UpdatedAt.Add(retryDelay(dbParams.ConsecutiveFailures)), Valid: true}
+	if retryAt, ok := retryAfter(header, dbParams.UpdatedAt); ok && retryAt.After(dbParams.NextFetchAt.Time) {
+		dbParams.NextFetchAt.Time = retryAt
+		if retryAt.After(dbParams.UpdatedAt.Add(feedMaxRetryDelay)) {
+			dbParams.NextFetchAt.Time = dbParams.UpdatedAt.Add(feedMaxRetryDelay)
+		}
+	}
+	if int(dbParams.ConsecutiveFailures) >= limit {
+		dbParams.DisabledAt = sql.NullTime{Time: dbParams.UpdatedAt, Valid: true}
+	}
+	err = s.db.RecordFeedFailure(ctx, dbParams)
+	if err != nil {
+		return fmt.Errorf("record feed failure database update query error: %v\n", err)
+	}
+	if dbParams.DisabledAt.Valid {
+		fmt.Printf("  Feed %s disabled after %d consecutive failures (use enablefeed to re-enable)\n", dbFeed.Name, dbParams.ConsecutiveFailures)
+	} else {
+		fmt.Printf("  Feed %s failed %d times in a row, retrying after %s\n", dbFeed.Name, dbParams.ConsecutiveFailures, dbParams.NextFetchAt.Time.Format(time.DateTime))
+	}
+	return nil
+}
+
+// Record successful fetch of feed, clearing any failures and re-enabling it
+// if disabled (e.g. when fetched with fetch), with its fetch interval and
+// time after which it is next eligible to be fetched
+func recordFeedSuccess(ctx context.Context, s *state, dbFeed database.Feed, interval sql.NullInt32, nextFetchAt sql.NullTime) error {
+	var (
+		dbParams database.RecordFeedSuccessParams
+		err      error
+	)
+	dbParams.ID = dbFeed.ID
+	dbParams.UpdatedAt = time.Now()
+	dbParams.NextFetchAt = nextFetchAt
+	dbParams.FetchInterval = interval
+	err = s.db.RecordFeedSuccess(ctx, dbParams)
+	if err != nil {
+		return fmt.Errorf("record feed success database update query error: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		failures int32
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{5, 16 * time.Minute},
+		{12, feedMaxRetryDelay},
+		{1000, feedMaxRetryDelay},
+	}
+	for _, tt := range tests {
+		got := retryDelay(tt.failures)
+		if got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		value string
+		want  time.Time
+		ok    bool
+	}{
+		{"", time.Time{}, false},
+		{"120", now.Add(2 * time.Minute), true},
+		{" 0 ", now, true},
+		{"Fri, 01 Mar 2024 18:30:00 GMT", time.Date(2024, 3, 1, 18, 30, 0, 0, time.UTC), true},
+		{"-5", time.Time{}, false},
+		{"soon", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		header.Set("Retry-After", tt.value)
+		got, ok := retryAfter(header, now)
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+		// TIMESTAMP columns store wall clock time, so it must be local
+		if ok && got.Location() != time.Local {
+			t.Errorf("retryAfter(%q) location = %v, want Local", tt.value, got.Location())
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/dragonicorn/gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// Records statements executed, standing in for the database
+type recordingDB struct {
+	queries []string
+}
+
+func (db *recordingDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	db.queries = append(db.queries, strings.SplitN(query, "\n", 2)[0])
+	// arrays must be convertible to values sent to the database
+	for _, arg := range args {
+		if valuer, ok := arg.(driver.Valuer); ok {
+			_, err := valuer.Value()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (db *recordingDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	panic("not supported")
+}
+
+func (db *recordingDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	panic("not supported")
+}
+
+func (db *recordingDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	panic("not supported")
+}
+
+// Return items with enclosures, authors and categories, and ids of their posts
+func batchTestItems(n int) ([]uuid.UUID, []RSSItem) {
+	var (
+		postIDs  []uuid.UUID
+		rssItems []RSSItem
+	)
+	for range n {
+		postIDs = append(postIDs, uuid.New())
+		rssItems = append(rssItems, RSSItem{
+			Enclosure: []RSSEnclosure{{URL: "https://example.com/a.mp3", Length: "100"}, {URL: "https://example.com/b.mp3"}},
+			Author:    []string{"Ann", "Bob"},
+			Category:  []string{"go", "rss", "feeds"},
+		})
+	}
+	return postIDs, rssItems
+}
+
+func TestCreateEnclosuresBatched(t *testing.T) {
+	var db recordingDB
+	s := state{db: database.New(&db)}
+	postIDs, rssItems := batchTestItems(100)
+	err := createEnclosures(context.Background(), &s, postIDs, rssItems)
+	if err != nil {
+		t.Fatalf("createEnclosures error: %v", err)
+	}
+	if len(db.queries) != 1 || db.queries[0] != "-- name: CreateEnclosures :exec" {
+		t.Errorf("createEnclosures queries = %q, want one CreateEnclosures", db.queries)
+	}
+	db = recordingDB{}
+	err = createEnclosures(context.Background(), &s, nil, nil)
+	if err != nil || len(db.queries) != 0 {
+		t.Errorf("createEnclosures with no items = %v, %q, want no queries", err, db.queries)
+	}
+}
+
+func TestCreateAuthorsAndCategoriesBatched(t *testing.T) {
+	var db recordingDB
+	s := state{db: database.New(&db)}
+	postIDs, rssItems := batchTestItems(100)
+	err := createAuthorsAndCategories(context.Background(), &s, postIDs, rssItems)
+	if err != nil {
+		t.Fatalf("createAuthorsAndCategories error: %v", err)
+	}
+	want := []string{
+		"-- name: CreateAuthors :exec",
+		"-- name: CreatePostAuthors :exec",
+		"-- name: CreateCategories :exec",
+		"-- name: CreatePostCategories :exec",
+	}
+	if strings.Join(db.queries, "\n") != strings.Join(want, "\n") {
+		t.Errorf("createAuthorsAndCategories queries = %q, want %q", db.queries, want)
+	}
+}
+
+func TestRevisePostsBatched(t *testing.T) {
+	var (
+		db       recordingDB
+		dbParams database.UpdatePostsContentParams
+	)
+	s := state{db: database.New(&db)}
+	for range 50 {
+		dbParams.Ids = append(dbParams.Ids, uuid.New())
+		dbParams.Titles = append(dbParams.Titles, "title")
+		dbParams.Descriptions = append(dbParams.Descriptions, "description")
+		dbParams.Contents = append(dbParams.Contents, "")
+		dbParams.ContentHashes = append(dbParams.ContentHashes, postContentHash("title", "description", ""))
+	}
+	err := revisePosts(context.Background(), &s, dbParams)
+	if err != nil {
+		t.Fatalf("revisePosts error: %v", err)
+	}
+	want := []string{"-- name: CreatePostRevisions :exec", "-- name: UpdatePostsContent :exec"}
+	if strings.Join(db.queries, "\n") != strings.Join(want, "\n") {
+		t.Errorf("revisePosts queries = %q, want %q", db.queries, want)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+// Characters for bytes 0x80-0x9F in windows-1252, zero where undefined
+var windows1252 = [32]rune{
+	0x20AC, 0, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0, 0x017D, 0,
+	0, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0, 0x017E, 0x0178,
+}
+
+// Characters in iso-8859-15 which differ from iso-8859-1
+var iso885915 = map[byte]rune{
+	0xA4: 0x20AC, 0xA6: 0x0160, 0xA8: 0x0161, 0xB4: 0x017D,
+	0xB8: 0x017E, 0xBC: 0x0152, 0xBD: 0x0153, 0xBE: 0x0178,
+}
+
+// Return canonical name of supported charset label, or "" if unsupported.
+// As in web browsers, iso-8859-1 and us-ascii are decoded as windows-1252,
+// since feeds declaring them frequently contain windows-1252 punctuation.
+func charsetName(label string) string {
+	switch strings.ToLower(strings.Trim(label, " \"'")) {
+	case "utf-8", "utf8", "unicode-1-1-utf-8":
+		return "utf-8"
+	case "windows-1252", "cp1252", "x-cp1252",
+		"iso-8859-1", "iso8859-1", "iso_8859-1", "iso88591", "latin1", "l1", "cp819",
+		"us-ascii", "ascii", "ansi_x3.4-1968":
+		return "windows-1252"
+	case "iso-8859-15", "iso8859-15", "iso_8859-15", "iso885915", "latin9", "latin-9", "l9":
+		return "iso-8859-15"
+	}
+	return ""
+}
+
+// Decode body from named legacy charset into UTF-8
+func decodeCharset(label string, body []byte) ([]byte, error) {
+	var (
+		name    string = charsetName(label)
+		decoded bytes.Buffer
+		r       rune
+		ok      bool
+	)
+	if name == "" {
+		return nil, fmt.Errorf("unsupported charset '%s'", label)
+	}
+	if name == "utf-8" {
+		return body, nil
+	}
+	decoded.Grow(len(body))
+	for _, b := range body {
+		r = rune(b)
+		switch {
+		case b < 0x80:
+		case name == "windows-1252" && b < 0xA0:
+			if windows1252[b-0x80] != 0 {
+				r = windows1252[b-0x80]
+			}
+		case name == "iso-8859-15":
+			if r, ok = iso885915[b]; !ok {
+				r = rune(b)
+			}
+		}
+		decoded.WriteRune(r)
+	}
+	return decoded.Bytes(), nil
+}
+
+// CharsetReader for xml.Decoder, used when XML prolog declares an encoding
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	var (
+		body []byte
+		err  error
+	)
+	body, err = io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	body, err = decodeCharset(label, body)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(body), nil
+}
+
+// Return XML decoder for feed body which decodes legacy charsets into UTF-8.
+// A legacy charset in the HTTP Content-Type takes precedence over the XML
+// prolog, otherwise the encoding declared in the XML prolog is used.
+func newFeedDecoder(body []byte, contentType string) (*xml.Decoder, error) {
+	var (
+		decoder *xml.Decoder
+		params  map[string]string
+		label   string
+		err     error
+	)
+	_, params, err = mime.ParseMediaType(contentType)
+	if err == nil {
+		label = params["charset"]
+	}
+	if label != "" && charsetName(label) != "utf-8" {
+		body, err = decodeCharset(label, body)
+		if err != nil {
+			return nil, err
+		}
+		decoder = xml.NewDecoder(bytes.NewReader(body))
+		// body is already UTF-8, so ignore encoding declared in XML prolog
+		decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+			return input, nil
+		}
+		return decoder, nil
+	}
+	decoder = xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charsetReader
+	return decoder, nil
+}
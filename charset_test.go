@@ -0,0 +1,63 @@
+package main
+
+import "testing"
+
+func TestDecodeCharset(t *testing.T) {
+	tests := []struct {
+		label string
+		in    string
+		want  string
+	}{
+		{"utf-8", "caf\xc3\xa9", "café"},
+		{"ISO-8859-1", "caf\xe9", "café"},
+		{"latin1", "\x93quoted\x94 \x85", "“quoted” …"},
+		{"windows-1252", "\x80 \x81", "€ \u0081"},
+		{"\"iso-8859-15\"", "\xa4 \xbd \xe9", "€ œ é"},
+	}
+	for _, tt := range tests {
+		got, err := decodeCharset(tt.label, []byte(tt.in))
+		if err != nil {
+			t.Errorf("decodeCharset(%q) error: %v", tt.label, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("decodeCharset(%q, %q) = %q, want %q", tt.label, tt.in, got, tt.want)
+		}
+	}
+	_, err := decodeCharset("shift_jis", []byte("x"))
+	if err == nil {
+		t.Errorf("decodeCharset(shift_jis) returned no error")
+	}
+}
+
+func TestNewFeedDecoder(t *testing.T) {
+	var item struct {
+		Title string `xml:"title"`
+	}
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+	}{
+		{"prolog", "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><item><title>caf\xe9</title></item>", "application/xml"},
+		{"content type", "<item><title>caf\xe9</title></item>", "application/xml; charset=windows-1252"},
+		{"content type overrides prolog", "<?xml version=\"1.0\" encoding=\"utf-8\"?><item><title>caf\xe9</title></item>", "text/xml; charset=iso-8859-1"},
+		{"utf-8", "<?xml version=\"1.0\" encoding=\"UTF-8\"?><item><title>caf\xc3\xa9</title></item>", ""},
+	}
+	for _, tt := range tests {
+		decoder, err := newFeedDecoder([]byte(tt.body), tt.contentType)
+		if err != nil {
+			t.Errorf("%s: newFeedDecoder error: %v", tt.name, err)
+			continue
+		}
+		item.Title = ""
+		err = decoder.Decode(&item)
+		if err != nil {
+			t.Errorf("%s: decode error: %v", tt.name, err)
+			continue
+		}
+		if item.Title != "café" {
+			t.Errorf("%s: title = %q, want %q", tt.name, item.Title, "café")
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Publication date layouts seen in feeds, tried in order after any leading
+// weekday has been removed. Day "2" also matches zero padded days.
+var pubDateLayouts = []string{
+	// RFC1123/RFC822 variants used by RSS pubDate
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04 MST",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04:05 MST",
+	"2 Jan 06 15:04 -0700",
+	"2 Jan 06 15:04 MST",
+	"2 January 2006 15:04:05 -0700",
+	"2 January 2006 15:04:05 MST",
+	"2 Jan 2006",
+	// RFC3339 and W3C date formats used by Atom, JSON Feed and dc:date
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// UTC offsets (in hours) of named timezones found in feeds, as time.Parse
+// treats abbreviations unknown to the local timezone as UTC
+var pubDateZones = map[string]float64{
+	"UT": 0, "UTC": 0, "GMT": 0, "Z": 0,
+	"EST": -5, "EDT": -4, "CST": -6, "CDT": -5,
+	"MST": -7, "MDT": -6, "PST": -8, "PDT": -7,
+	"AKST": -9, "AKDT": -8, "HST": -10,
+	"BST": 1, "IST": 5.5, "WET": 0, "WEST": 1,
+	"CET": 1, "CEST": 2, "MET": 1, "MEST": 2,
+	"EET": 2, "EEST": 3, "MSK": 3,
+	"JST": 9, "KST": 9, "HKT": 8, "SGT": 8,
+	"AWST": 8, "ACST": 9.5, "ACDT": 10.5, "AEST": 10, "AEDT": 11,
+	"NZST": 12, "NZDT": 13,
+}
+
+// Parse feed publication date, trying each of the known layouts
+func parsePubDate(s string) (time.Time, error) {
+	var (
+		i     int
+		t     time.Time
+		err   error
+		name  string
+		hours float64
+		ok    bool
+	)
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return time.Time{}, fmt.Errorf("missing publication date")
+	}
+	// drop trailing comment, e.g. "+0000 (UTC)"
+	i = strings.Index(s, " (")
+	if i > 0 && strings.HasSuffix(s, ")") {
+		s = s[:i]
+	}
+	// drop leading weekday, which is redundant and often misspelt
+	i = strings.Index(s, ",")
+	if i > 0 && i < 10 {
+		s = strings.TrimSpace(s[i+1:])
+	}
+	for _, layout := range pubDateLayouts {
+		t, err = time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		name, _ = t.Zone()
+		hours, ok = pubDateZones[strings.ToUpper(name)]
+		if ok {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.FixedZone(name, int(hours*3600)))
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognised publication date '%s'", s)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePubDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"Mon, 02 Jan 2006 15:04:05 -0700", time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC)},
+		{"Mon, 2 Jan 2006 15:04:05 GMT", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"Tues, 02 Jan 2006 15:04:05 EST", time.Date(2006, 1, 2, 20, 4, 5, 0, time.UTC)},
+		{"02 Jan 2006 15:04 +0000 (UTC)", time.Date(2006, 1, 2, 15, 4, 0, 0, time.UTC)},
+		{"  Mon,  02 Jan 06 15:04:05 PDT ", time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC)},
+		{"2 January 2006 15:04:05 +0100", time.Date(2006, 1, 2, 14, 4, 5, 0, time.UTC)},
+		{"2006-01-02T15:04:05Z", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2006-01-02T15:04:05.123+02:00", time.Date(2006, 1, 2, 13, 4, 5, 123000000, time.UTC)},
+		{"2006-01-02T15:04+01:00", time.Date(2006, 1, 2, 14, 4, 0, 0, time.UTC)},
+		{"2006-01-02", time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"2006-01", time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parsePubDate(tt.in)
+		if err != nil {
+			t.Errorf("parsePubDate(%q) error: %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parsePubDate(%q) = %v, want %v", tt.in, got.UTC(), tt.want)
+		}
+	}
+}
+
+func TestParsePubDateInvalid(t *testing.T) {
+	for _, in := range []string{"", "   ", "yesterday", "32 Jan 2006 15:04:05 GMT", "2006-13-01"} {
+		_, err := parsePubDate(in)
+		if err == nil {
+			t.Errorf("parsePubDate(%q) returned no error", in)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Link types advertising a feed in an HTML page
+var feedLinkTypes = []string{
+	"application/rss+xml",
+	"application/atom+xml",
+	"application/feed+json",
+	"application/rdf+xml",
+}
+
+// Common feed paths tried when page advertises no feed
+var feedPaths = []string{
+	"/feed",
+	"/rss",
+	"/index.xml",
+	"/feed.xml",
+	"/atom.xml",
+	"/rss.xml",
+	"/feed.json",
+}
+
+var (
+	htmlLinkTag  = regexp.MustCompile(`(?is)<link\b[^>]*>`)
+	htmlBaseTag  = regexp.MustCompile(`(?is)<base\b[^>]*>`)
+	htmlTagAttrs = regexp.MustCompile(`(?s)([a-zA-Z_:][-a-zA-Z0-9_:.]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// Return attributes of HTML tag, with lower case names and unescaped values
+func htmlAttrs(tag string) map[string]string {
+	var attrs map[string]string = make(map[string]string)
+	for _, m := range htmlTagAttrs.FindAllStringSubmatch(tag, -1) {
+		attrs[strings.ToLower(m[1])] = html.UnescapeString(m[2] + m[3] + m[4])
+	}
+	return attrs
+}
+
+// Report whether response body is a feed that fetchFeed can parse
+func isFeed(header http.Header, body []byte) bool {
+	if len(body) == 0 {
+		return false
+	}
+	if isJSONFeed(header.Get("Content-Type"), body) {
+		return true
+	}
+	decoder, err := newFeedDecoder(body, header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	root, err := feedRoot(decoder)
+	if err != nil {
+		return false
+	}
+	switch root.Name.Local {
+	case "rss", "feed", "RDF":
+		return true
+	}
+	return false
+}
+
+// Report whether response body is an HTML page, by its content type or, when
+// it has none, by sniffing the body
+func isHTML(header http.Header, body []byte) bool {
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// Return feed URLs advertised by <link rel="alternate"> tags in HTML page
+func feedLinks(pageURL *url.URL, body []byte) []string {
+	var (
+		attrs map[string]string
+		base  *url.URL = pageURL
+		href  *url.URL
+		links []string
+		err   error
+	)
+	tag := htmlBaseTag.Find(body)
+	if tag != nil {
+		href, err = pageURL.Parse(htmlAttrs(string(tag))["href"])
+		if err == nil {
+			base = href
+		}
+	}
+	for _, tag = range htmlLinkTag.FindAll(body, -1) {
+		attrs = htmlAttrs(string(tag))
+		if !strings.Contains(" "+strings.ToLower(attrs["rel"])+" ", " alternate ") {
+			continue
+		}
+		for _, feedType := range feedLinkTypes {
+			if strings.EqualFold(strings.TrimSpace(attrs["type"]), feedType) && attrs["href"] != "" {
+				href, err = base.Parse(strings.TrimSpace(attrs["href"]))
+				if err == nil {
+					links = append(links, href.String())
+				}
+				break
+			}
+		}
+	}
+	return links
+}
+
+// Return URL of feed for feedURL, which is returned unchanged unless it is an
+// HTML page (or cannot be fetched). The feed of an HTML page is discovered
+// from its <link> tags, or by trying common feed paths on the same site.
+func discoverFeed(ctx context.Context, feedURL string) (string, error) {
+	var (
+		body    []byte
+		header  http.Header
+		links   []string
+		pageURL *url.URL
+		pathURL *url.URL
+		err     error
+	)
+	pageURL, err = url.Parse(feedURL)
+	if err != nil {
+		return "", err
+	}
+	body, header, err = getURL(ctx, feedURL)
+	if err != nil {
+		// may be a feed whose host is down for now, which agg retries later
+		fmt.Printf("Unable to fetch '%s' (%v), using it as feed url\n", feedURL, err)
+		return feedURL, nil
+	}
+	if isFeed(header, body) || !isHTML(header, body) {
+		return feedURL, nil
+	}
+	fmt.Printf("'%s' is not a feed, looking for feeds advertised by page...\n", feedURL)
+	links = feedLinks(pageURL, body)
+	if len(links) == 0 {
+		fmt.Println("No feeds advertised by page, trying common feed paths...")
+		for _, path := range feedPaths {
+			pathURL, err = pageURL.Parse(path)
+			if err != nil {
+				continue
+			}
+			body, header, err = getURL(ctx, pathURL.String())
+			if err == nil && isFeed(header, body) {
+				links = append(links, pathURL.String())
+				break
+			}
+		}
+	}
+	if len(links) == 0 {
+		return "", fmt.Errorf("no feed found at '%s'", feedURL)
+	}
+	fmt.Println("Discovered feeds:")
+	for i, link := range links {
+		fmt.Printf("* %s", link)
+		if i == 0 {
+			fmt.Printf(" (selected)")
+		}
+		fmt.Println()
+	}
+	return links[0], nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+)
+
+func TestFeedLinks(t *testing.T) {
+	page, _ := url.Parse("https://example.com/blog/post")
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"none", `<html><head><link rel="stylesheet" href="/s.css"></head></html>`, nil},
+		{"relative rss", `<link rel="alternate" type="application/rss+xml" href="/feed.xml">`, []string{"https://example.com/feed.xml"}},
+		{"attribute order and case", `<LINK HREF='atom.xml' TYPE='Application/Atom+XML' REL='Alternate'>`, []string{"https://example.com/blog/atom.xml"}},
+		{"several rels", `<link rel="home alternate" type="application/feed+json" href="https://feeds.example.org/j.json">`, []string{"https://feeds.example.org/j.json"}},
+		{"not a feed type", `<link rel="alternate" type="text/html" hreflang="fr" href="/fr/">`, nil},
+		{"base", `<base href="https://cdn.example.net/x/"><link rel="alternate" type="application/rss+xml" href="rss?a=1&amp;b=2">`, []string{"https://cdn.example.net/x/rss?a=1&b=2"}},
+		{"multiple", `<link rel="alternate" type="application/rss+xml" href="/rss"><link rel="alternate" type="application/atom+xml" href="/atom">`, []string{"https://example.com/rss", "https://example.com/atom"}},
+	}
+	for _, tt := range tests {
+		got := feedLinks(page, []byte(tt.body))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: feedLinks = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsFeed(t *testing.T) {
+	tests := []struct {
+		contentType string
+		body        string
+		want        bool
+	}{
+		{"application/rss+xml", `<?xml version="1.0"?><rss version="2.0"><channel></channel></rss>`, true},
+		{"application/atom+xml", `<feed xmlns="http://www.w3.org/2005/Atom"></feed>`, true},
+		{"application/rdf+xml", `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"></rdf:RDF>`, true},
+		{"application/feed+json", `{"version": "https://jsonfeed.org/version/1.1", "title": "x", "items": []}`, true},
+		{"text/html", `<!DOCTYPE html><html><body>page</body></html>`, false},
+		{"text/html", ``, false},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		header.Set("Content-Type", tt.contentType)
+		got := isFeed(header, []byte(tt.body))
+		if got != tt.want {
+			t.Errorf("isFeed(%q, %q) = %v, want %v", tt.contentType, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestIsHTML(t *testing.T) {
+	tests := []struct {
+		contentType string
+		body        string
+		want        bool
+	}{
+		{"text/html; charset=utf-8", "", true},
+		{"application/xhtml+xml", "", true},
+		{"application/rss+xml", "<rss></rss>", false},
+		{"text/plain", "<html></html>", false},
+		{"", "<!DOCTYPE html><html><body>page</body></html>", true},
+		{"", "<?xml version=\"1.0\"?><rss></rss>", false},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.contentType != "" {
+			header.Set("Content-Type", tt.contentType)
+		}
+		got := isHTML(header, []byte(tt.body))
+		if got != tt.want {
+			t.Errorf("isHTML(%q, %q) = %v, want %v", tt.contentType, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestDiscoverFeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<link rel="alternate" type="application/rss+xml" href="/feed.xml">`))
+		case "/feed.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			w.Write([]byte(`<rss version="2.0"><channel></channel></rss>`))
+		case "/text":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("not a feed"))
+		default:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	tests := []struct {
+		name    string
+		feedURL string
+		want    string
+	}{
+		{"feed", server.URL + "/feed.xml", server.URL + "/feed.xml"},
+		{"page", server.URL + "/page", server.URL + "/feed.xml"},
+		{"not html", server.URL + "/text", server.URL + "/text"},
+		{"server error", server.URL + "/down.xml", server.URL + "/down.xml"},
+		{"host down", closed.URL + "/feed.xml", closed.URL + "/feed.xml"},
+	}
+	for _, tt := range tests {
+		got, err := discoverFeed(context.Background(), tt.feedURL)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: discoverFeed = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
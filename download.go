@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dragonicorn/gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// Replace characters which are unsafe in file names
+func safeFileName(name string) string {
+	var safe strings.Builder
+	for _, r := range strings.TrimSpace(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			safe.WriteRune(r)
+		default:
+			safe.WriteRune('_')
+		}
+	}
+	return strings.Trim(safe.String(), "._")
+}
+
+// Return local path of enclosure download, in a directory for the feed and
+// named by publication date, enclosure id and the file name in its URL
+func downloadPath(dir string, dbFeed database.Feed, enclosure database.GetEnclosuresForFeedRow) string {
+	var base string = "enclosure"
+	u, err := url.Parse(enclosure.Url)
+	if err == nil && safeFileName(path.Base(u.Path)) != "" {
+		base = safeFileName(path.Base(u.Path))
+	}
+	return filepath.Join(dir, safeFileName(dbFeed.Name), fmt.Sprintf("%s-%s-%s", enclosure.PostPublishedAt.Format("2006-01-02"), enclosure.ID.String()[:8], base))
+}
+
+// Parse Content-Range header of response to range request, returning start
+// of range and size of whole file (-1 if unknown), or start -1 if unsatisfied
+func contentRange(value string) (int64, int64, bool) {
+	var (
+		first string
+		total string
+		start int64 = -1
+		size  int64 = -1
+		err   error
+	)
+	value, ok := strings.CutPrefix(strings.TrimSpace(value), "bytes ")
+	if !ok {
+		return 0, 0, false
+	}
+	first, total, ok = strings.Cut(value, "/")
+	if !ok {
+		return 0, 0, false
+	}
+	if first != "*" {
+		first, _, ok = strings.Cut(first, "-")
+		if !ok {
+			return 0, 0, false
+		}
+		start, err = strconv.ParseInt(first, 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+	}
+	if total != "*" {
+		size, err = strconv.ParseInt(total, 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, size, true
+}
+
+// Download URL to file, resuming from partial download in file.part if the
+// server supports range requests, and starting again if the partial download
+// does not match the file on the server (length is that of the enclosure, 0
+// if unknown). Returns size of downloaded file.
+func downloadFile(ctx context.Context, fileURL string, filePath string, length int64) (int64, error) {
+	var (
+		client   http.Client
+		file     *os.File
+		info     os.FileInfo
+		offset   int64
+		partPath string = filePath + ".part"
+		req      *http.Request
+		resp     *http.Response
+		n        int64
+		err      error
+	)
+	err = os.MkdirAll(filepath.Dir(filePath), 0o755)
+	if err != nil {
+		return 0, err
+	}
+	file, err = os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	info, err = file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	offset = info.Size()
+	req, err = http.NewRequestWithContext(ctx, "GET", fileURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "gator")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err = client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		start, _, ok := contentRange(resp.Header.Get("Content-Range"))
+		if (!ok || start != offset) && offset == 0 {
+			return 0, fmt.Errorf("unable to download %s: unexpected range %s", fileURL, resp.Header.Get("Content-Range"))
+		}
+		if !ok || start != offset {
+			resp.Body.Close()
+			return restartDownload(ctx, fileURL, filePath, length, file)
+		}
+		fmt.Printf("resuming download of %s from %d bytes\n", fileURL, offset)
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// partial download holds the whole file only if it is the right size
+		_, size, ok := contentRange(resp.Header.Get("Content-Range"))
+		if (ok && size >= 0 && size != offset) || (length > 0 && length != offset) {
+			resp.Body.Close()
+			return restartDownload(ctx, fileURL, filePath, length, file)
+		}
+	case resp.StatusCode > 299:
+		return 0, fmt.Errorf("unable to download %s: %s", fileURL, resp.Status)
+	default:
+		// server ignored range request, so start again from the beginning
+		offset = 0
+		err = file.Truncate(0)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if resp.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		_, err = file.Seek(offset, io.SeekStart)
+		if err != nil {
+			return 0, err
+		}
+		n, err = io.Copy(file, resp.Body)
+		if err != nil {
+			return 0, err
+		}
+	}
+	err = file.Close()
+	if err != nil {
+		return 0, err
+	}
+	err = os.Rename(partPath, filePath)
+	if err != nil {
+		return 0, err
+	}
+	return offset + n, nil
+}
+
+// Discard partial download which does not match file on server, and download
+// it again from the beginning
+func restartDownload(ctx context.Context, fileURL string, filePath string, length int64, file *os.File) (int64, error) {
+	var err error
+	fmt.Printf("partial download of %s does not match file on server, downloading again\n", fileURL)
+	err = file.Truncate(0)
+	if err != nil {
+		return 0, err
+	}
+	err = file.Close()
+	if err != nil {
+		return 0, err
+	}
+	return downloadFile(ctx, fileURL, filePath, length)
+}
+
+// Download enclosures of most recent posts of feed, keeping those of the
+// last N posts (from feed or configuration, 0 keeps all) and removing older
+// downloads
+func downloadFeed(ctx context.Context, s *state, dbFeed database.Feed) error {
+	var (
+		dbDownload   database.Download
+		dbEnclosures []database.GetEnclosuresForFeedRow
+		dbParams     database.UpsertDownloadParams
+		dir          string = s.config.DownloadPath()
+		filePath     string
+		keep         int = s.config.DownloadKeep
+		posts        int
+		postID       uuid.UUID
+		size         int64
+		err          error
+	)
+	if dbFeed.DownloadKeep.Valid {
+		keep = int(dbFeed.DownloadKeep.Int32)
+	}
+	dbEnclosures, err = s.db.GetEnclosuresForFeed(ctx, dbFeed.ID)
+	if err != nil {
+		return fmt.Errorf("enclosures database select query error: %v", err)
+	}
+	for _, enclosure := range dbEnclosures {
+		// enclosures of each post are together, most recent post first
+		if posts == 0 || enclosure.PostID != postID {
+			posts++
+			postID = enclosure.PostID
+		}
+		dbDownload, err = s.db.GetDownloadForEnclosure(ctx, enclosure.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("download database select query error: %v", err)
+		}
+		found := err == nil
+		if keep > 0 && posts > keep {
+			// Remove download older than the last N posts of this feed
+			if found && dbDownload.FeedID == dbFeed.ID {
+				err = os.Remove(dbDownload.Path)
+				if err != nil && !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+				os.Remove(dbDownload.Path + ".part")
+				err = s.db.DeleteDownload(ctx, dbDownload.ID)
+				if err != nil {
+					return fmt.Errorf("download database delete query error: %v", err)
+				}
+				fmt.Printf("removed old download %s\n", dbDownload.Path)
+			}
+			continue
+		}
+		if found && dbDownload.CompletedAt.Valid {
+			_, err = os.Stat(dbDownload.Path)
+			if err == nil {
+				// already on disk, for this or another feed
+				continue
+			}
+		}
+		filePath = downloadPath(dir, dbFeed, enclosure)
+		fmt.Printf("downloading %s to %s...\n", enclosure.Url, filePath)
+		size, err = downloadFile(ctx, enclosure.Url, filePath, enclosure.Length.Int64)
+		if err != nil {
+			// leave partial download to be resumed and carry on with others
+			fmt.Printf("download error: %v\n", err)
+			continue
+		}
+		// Record download in database
+		dbParams.ID = uuid.New()
+		dbParams.CreatedAt = time.Now()
+		dbParams.UpdatedAt = dbParams.CreatedAt
+		dbParams.EnclosureID = enclosure.ID
+		dbParams.FeedID = dbFeed.ID
+		dbParams.Path = filePath
+		dbParams.Size = size
+		dbParams.CompletedAt = sql.NullTime{Time: dbParams.CreatedAt, Valid: true}
+		err = s.db.UpsertDownload(ctx, dbParams)
+		if err != nil {
+			return fmt.Errorf("download database insert query error: %v", err)
+		}
+		fmt.Printf("downloaded %d bytes to %s\n", size, filePath)
+	}
+	return nil
+}
+
+// Download enclosures of all feeds followed by user
+func downloadFollowedFeeds(ctx context.Context, s *state, userName string) error {
+	var (
+		dbFeed        database.Feed
+		dbFeedFollows []database.GetFeedFollowsForUserRow
+		err           error
+	)
+	dbFeedFollows, err = s.db.GetFeedFollowsForUser(ctx, userName)
+	if err != nil {
+		return fmt.Errorf("feed follows database select query error: %v", err)
+	}
+	for _, feedFollow := range dbFeedFollows {
+		dbFeed, err = s.db.GetFeedById(ctx, feedFollow.FeedID)
+		if err != nil {
+			return fmt.Errorf("feed database select query error: %v", err)
+		}
+		fmt.Printf("  Downloading enclosures of feed %s...\n", dbFeed.Name)
+		err = downloadFeed(ctx, s, dbFeed)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContentRange(t *testing.T) {
+	tests := []struct {
+		value string
+		start int64
+		size  int64
+		ok    bool
+	}{
+		{"bytes 100-199/200", 100, 200, true},
+		{"bytes 0-99/*", 0, -1, true},
+		{"bytes */200", -1, 200, true},
+		{"", 0, 0, false},
+		{"items 0-1/2", 0, 0, false},
+		{"bytes x-1/2", 0, 0, false},
+	}
+	for _, tt := range tests {
+		start, size, ok := contentRange(tt.value)
+		if start != tt.start || size != tt.size || ok != tt.ok {
+			t.Errorf("contentRange(%q) = %d, %d, %v, want %d, %d, %v", tt.value, start, size, ok, tt.start, tt.size, tt.ok)
+		}
+	}
+}
+
+func TestDownloadFile(t *testing.T) {
+	const content = "0123456789"
+	serveContent := func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file", time.Time{}, strings.NewReader(content))
+	}
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		part    string
+		length  int64
+	}{
+		{"new download", serveContent, "", 0},
+		{"resumed download", serveContent, "01234", 10},
+		{"partial download longer than file", serveContent, "0123456789AB", 0},
+		{"range not continuing partial download", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				w.Header().Set("Content-Range", "bytes 0-9/10")
+				w.WriteHeader(http.StatusPartialContent)
+				w.Write([]byte(content))
+				return
+			}
+			w.Write([]byte(content))
+		}, "01234", 0},
+		{"unsatisfied range with wrong length", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+			w.Write([]byte(content))
+		}, "old", 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+			filePath := filepath.Join(t.TempDir(), "file")
+			if tt.part != "" {
+				err := os.WriteFile(filePath+".part", []byte(tt.part), 0o644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			size, err := downloadFile(context.Background(), server.URL, filePath, tt.length)
+			if err != nil {
+				t.Fatalf("downloadFile error: %v", err)
+			}
+			got, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, []byte(content)) || size != int64(len(content)) {
+				t.Errorf("downloaded %q (size %d), want %q", got, size, content)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dragonicorn/gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// Media file attached to item, from RSS enclosure or Media RSS media:content
+type RSSEnclosure struct {
+	URL      string `xml:"url,attr"`
+	Type     string `xml:"type,attr"`
+	Length   string `xml:"length,attr"`
+	FileSize string `xml:"fileSize,attr"`
+	Duration string `xml:"duration,attr"`
+}
+
+// Return enclosures of item, removing duplicates of the same URL
+func (rssItem RSSItem) enclosures() []RSSEnclosure {
+	var (
+		enclosures []RSSEnclosure
+		seen       map[string]bool = make(map[string]bool)
+	)
+	for _, list := range [][]RSSEnclosure{rssItem.Enclosure, rssItem.MediaContent, rssItem.MediaGroup} {
+		for _, enclosure := range list {
+			enclosure.URL = strings.TrimSpace(enclosure.URL)
+			if enclosure.URL == "" || seen[enclosure.URL] {
+				continue
+			}
+			seen[enclosure.URL] = true
+			enclosures = append(enclosures, enclosure)
+		}
+	}
+	return enclosures
+}
+
+// Parse integer attribute (e.g. length or episode), NULL if not an integer
+func nullInt64(s string) sql.NullInt64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	return sql.NullInt64{Int64: n, Valid: err == nil && n >= 0}
+}
+
+func nullInt32(s string) sql.NullInt32 {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 32)
+	return sql.NullInt32{Int32: int32(n), Valid: err == nil && n >= 0}
+}
+
+// Parse duration in seconds from seconds, MM:SS or HH:MM:SS, NULL if invalid
+func parseDuration(s string) sql.NullInt32 {
+	var (
+		parts   []string = strings.Split(strings.TrimSpace(s), ":")
+		seconds float64
+		f       float64
+		err     error
+	)
+	if s == "" || len(parts) > 3 {
+		return sql.NullInt32{}
+	}
+	for _, part := range parts {
+		f, err = strconv.ParseFloat(part, 64)
+		if err != nil || f < 0 {
+			return sql.NullInt32{}
+		}
+		seconds = seconds*60 + f
+	}
+	return sql.NullInt32{Int32: int32(seconds), Valid: true}
+}
+
+// Format duration in seconds as H:MM:SS
+func formatDuration(seconds int32) string {
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// Return value of nullable integer, or -1 (stored as NULL) if not valid
+func int64OrNone(n sql.NullInt64) int64 {
+	if !n.Valid {
+		return -1
+	}
+	return n.Int64
+}
+
+func int32OrNone(n sql.NullInt32) int32 {
+	if !n.Valid {
+		return -1
+	}
+	return n.Int32
+}
+
+// Create enclosures of items in database for their posts, in one query
+func createEnclosures(ctx context.Context, s *state, postIDs []uuid.UUID, rssItems []RSSItem) error {
+	var (
+		dbParams database.CreateEnclosuresParams
+		length   sql.NullInt64
+		duration sql.NullInt32
+	)
+	dbParams.CreatedAt = time.Now()
+	for i, rssItem := range rssItems {
+		for _, enclosure := range rssItem.enclosures() {
+			length = nullInt64(enclosure.Length)
+			if !length.Valid {
+				length = nullInt64(enclosure.FileSize)
+			}
+			duration = parseDuration(enclosure.Duration)
+			if !duration.Valid {
+				duration = parseDuration(rssItem.ITunesDuration)
+			}
+			dbParams.Ids = append(dbParams.Ids, uuid.New())
+			dbParams.PostIds = append(dbParams.PostIds, postIDs[i])
+			dbParams.Urls = append(dbParams.Urls, enclosure.URL)
+			dbParams.MimeTypes = append(dbParams.MimeTypes, strings.TrimSpace(enclosure.Type))
+			dbParams.Lengths = append(dbParams.Lengths, int64OrNone(length))
+			dbParams.Durations = append(dbParams.Durations, int32OrNone(duration))
+			dbParams.Episodes = append(dbParams.Episodes, int32OrNone(nullInt32(rssItem.ITunesEpisode)))
+			dbParams.Seasons = append(dbParams.Seasons, int32OrNone(nullInt32(rssItem.ITunesSeason)))
+		}
+	}
+	if len(dbParams.Ids) == 0 {
+		return nil
+	}
+	return s.db.CreateEnclosures(ctx, dbParams)
+}
+
+// Print enclosures of post from database
+func printEnclosures(ctx context.Context, s *state, postID uuid.UUID) error {
+	var (
+		dbEnclosures []database.Enclosure
+		err          error
+	)
+	dbEnclosures, err = s.db.GetEnclosuresForPost(ctx, postID)
+	if err != nil {
+		return err
+	}
+	for _, enclosure := range dbEnclosures {
+		fmt.Printf("\tEnclosure = %s\n", enclosure.Url)
+		if enclosure.MimeType.Valid {
+			fmt.Printf("\t\tType = %s\n", enclosure.MimeType.String)
+		}
+		if enclosure.Length.Valid {
+			fmt.Printf("\t\tLength = %d bytes\n", enclosure.Length.Int64)
+		}
+		if enclosure.Duration.Valid {
+			fmt.Printf("\t\tDuration = %s\n", formatDuration(enclosure.Duration.Int32))
+		}
+		if enclosure.Season.Valid {
+			fmt.Printf("\t\tSeason = %d\n", enclosure.Season.Int32)
+		}
+		if enclosure.Episode.Valid {
+			fmt.Printf("\t\tEpisode = %d\n", enclosure.Episode.Int32)
+		}
+	}
+	return nil
+}
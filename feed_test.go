@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Fetch feed body served with content type from a test server
+func fetchTestFeed(t *testing.T, contentType string, body string) *RSSFeed {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	rssFeed, _, err := fetchFeed(context.Background(), server.URL, "", "")
+	if err != nil {
+		t.Fatalf("fetchFeed error: %v", err)
+	}
+	return rssFeed
+}
+
+func TestFetchFeedRSSNamespacedElements(t *testing.T) {
+	rssFeed := fetchTestFeed(t, "application/rss+xml", `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" xmlns:media="http://search.yahoo.com/mrss/">
+<channel>
+<title>Show</title>
+<itunes:title>Show (podcast)</itunes:title>
+<link>https://example.com/</link>
+<item>
+<title>café</title>
+<itunes:title>other</itunes:title>
+<media:title>media other</media:title>
+<description>text</description>
+<media:description>media text</media:description>
+<link>https://example.com/1</link>
+<atom:link rel="self" href="https://example.com/1.xml"/>
+</item>
+</channel>
+</rss>`)
+	if rssFeed.Channel.Title != "Show" {
+		t.Errorf("channel title = %q, want %q", rssFeed.Channel.Title, "Show")
+	}
+	if len(rssFeed.Channel.Item) != 1 {
+		t.Fatalf("got %d items, want 1", len(rssFeed.Channel.Item))
+	}
+	rssItem := rssFeed.Channel.Item[0]
+	if rssItem.Title != "café" {
+		t.Errorf("item title = %q, want %q", rssItem.Title, "café")
+	}
+	if rssItem.Link != "https://example.com/1" {
+		t.Errorf("item link = %q, want %q", rssItem.Link, "https://example.com/1")
+	}
+	if rssItem.Description != "text" {
+		t.Errorf("item description = %q, want %q", rssItem.Description, "text")
+	}
+}
@@ -4,13 +4,30 @@ import (
 	"encoding/json"
 	"log"
 	"os"
+	"time"
 )
 
 const configFileName = ".gatorconfig.json"
 
+const defaultDownloadDir = "gator-downloads"
+
+const defaultFailureLimit = 10
+
+const (
+	defaultWorkers      = 8
+	defaultHostWorkers  = 2
+	defaultFetchTimeout = 60
+)
+
 type Config struct {
-	DbURL    string `json:"db_url"`
-	UserName string `json:"current_user_name"`
+	DbURL        string `json:"db_url"`
+	UserName     string `json:"current_user_name"`
+	DownloadDir  string `json:"download_dir,omitempty"`
+	DownloadKeep int    `json:"download_keep,omitempty"`
+	FailureLimit int    `json:"failure_limit,omitempty"`
+	Workers      int    `json:"workers,omitempty"`
+	HostWorkers  int    `json:"host_workers,omitempty"`
+	FetchTimeout int    `json:"fetch_timeout,omitempty"`
 }
 
 func getConfigFilePath() (string, error) {
@@ -35,6 +52,50 @@ func Read() Config {
 	return config
 }
 
+// Return directory for downloaded enclosures, by default in home directory
+func (cfg *Config) DownloadPath() string {
+	if cfg.DownloadDir != "" {
+		return cfg.DownloadDir
+	}
+	hd, err := os.UserHomeDir()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return hd + "/" + defaultDownloadDir
+}
+
+// Return number of consecutive fetch failures after which a feed is disabled
+func (cfg *Config) FeedFailureLimit() int {
+	if cfg.FailureLimit > 0 {
+		return cfg.FailureLimit
+	}
+	return defaultFailureLimit
+}
+
+// Return number of feeds fetched concurrently by agg
+func (cfg *Config) ScrapeWorkers() int {
+	if cfg.Workers > 0 {
+		return cfg.Workers
+	}
+	return defaultWorkers
+}
+
+// Return number of feeds on the same host fetched concurrently by agg
+func (cfg *Config) ScrapeHostWorkers() int {
+	if cfg.HostWorkers > 0 {
+		return cfg.HostWorkers
+	}
+	return defaultHostWorkers
+}
+
+// Return time allowed to fetch and store a feed, from seconds in config
+func (cfg *Config) ScrapeTimeout() time.Duration {
+	if cfg.FetchTimeout > 0 {
+		return time.Duration(cfg.FetchTimeout) * time.Second
+	}
+	return defaultFetchTimeout * time.Second
+}
+
 func (cfg *Config) SetUser(user string) {
 	cfg.UserName = user
 	text, err := json.Marshal(cfg)
@@ -7,11 +7,131 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const claimFeed = `-- name: ClaimFeed :one
+UPDATE feeds
+SET updated_at = $1::timestamp, claimed_until = $2::timestamp
+WHERE id = $3 AND (claimed_until IS NULL OR claimed_until <= $1::timestamp)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, site_title, site_link, description, language, image_url, generator, download_keep, etag, last_modified, consecutive_failures, last_error, last_success_at, next_fetch_at, disabled_at, ttl, skip_hours, skip_days, fetch_interval, claimed_until
+`
+
+type ClaimFeedParams struct {
+	Now          time.Time
+	ClaimedUntil time.Time
+	ID           uuid.UUID
+}
+
+func (q *Queries) ClaimFeed(ctx context.Context, arg ClaimFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, claimFeed, arg.Now, arg.ClaimedUntil, arg.ID)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.SiteTitle,
+		&i.SiteLink,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+		&i.DownloadKeep,
+		&i.Etag,
+		&i.LastModified,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastSuccessAt,
+		&i.NextFetchAt,
+		&i.DisabledAt,
+		&i.Ttl,
+		pq.Array(&i.SkipHours),
+		pq.Array(&i.SkipDays),
+		&i.FetchInterval,
+		&i.ClaimedUntil,
+	)
+	return i, err
+}
+
+const claimFeedsToFetch = `-- name: ClaimFeedsToFetch :many
+UPDATE feeds
+SET updated_at = $1::timestamp, claimed_until = $2::timestamp
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE disabled_at IS NULL
+    AND (next_fetch_at IS NULL OR next_fetch_at <= $1::timestamp)
+    AND (claimed_until IS NULL OR claimed_until <= $1::timestamp)
+    ORDER BY next_fetch_at ASC NULLS FIRST, last_fetched_at ASC NULLS FIRST
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, site_title, site_link, description, language, image_url, generator, download_keep, etag, last_modified, consecutive_failures, last_error, last_success_at, next_fetch_at, disabled_at, ttl, skip_hours, skip_days, fetch_interval, claimed_until
+`
+
+type ClaimFeedsToFetchParams struct {
+	Now          time.Time
+	ClaimedUntil time.Time
+	MaxFeeds     int32
+}
+
+func (q *Queries) ClaimFeedsToFetch(ctx context.Context, arg ClaimFeedsToFetchParams) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, claimFeedsToFetch, arg.Now, arg.ClaimedUntil, arg.MaxFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.SiteTitle,
+			&i.SiteLink,
+			&i.Description,
+			&i.Language,
+			&i.ImageUrl,
+			&i.Generator,
+			&i.DownloadKeep,
+			&i.Etag,
+			&i.LastModified,
+			&i.ConsecutiveFailures,
+			&i.LastError,
+			&i.LastSuccessAt,
+			&i.NextFetchAt,
+			&i.DisabledAt,
+			&i.Ttl,
+			pq.Array(&i.SkipHours),
+			pq.Array(&i.SkipDays),
+			&i.FetchInterval,
+			&i.ClaimedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
 VALUES (
@@ -22,7 +142,7 @@ VALUES (
     $5,
     $6
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, site_title, site_link, description, language, image_url, generator, download_keep, etag, last_modified, consecutive_failures, last_error, last_success_at, next_fetch_at, disabled_at, ttl, skip_hours, skip_days, fetch_interval, claimed_until
 `
 
 type CreateFeedParams struct {
@@ -52,6 +172,25 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.SiteTitle,
+		&i.SiteLink,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+		&i.DownloadKeep,
+		&i.Etag,
+		&i.LastModified,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastSuccessAt,
+		&i.NextFetchAt,
+		&i.DisabledAt,
+		&i.Ttl,
+		pq.Array(&i.SkipHours),
+		pq.Array(&i.SkipDays),
+		&i.FetchInterval,
+		&i.ClaimedUntil,
 	)
 	return i, err
 }
@@ -65,8 +204,24 @@ func (q *Queries) DeleteFeeds(ctx context.Context) error {
 	return err
 }
 
+const enableFeed = `-- name: EnableFeed :exec
+UPDATE feeds
+SET updated_at = $2, consecutive_failures = 0, last_error = NULL, next_fetch_at = NULL, disabled_at = NULL
+WHERE $1 = id
+`
+
+type EnableFeedParams struct {
+	ID        uuid.UUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) EnableFeed(ctx context.Context, arg EnableFeedParams) error {
+	_, err := q.db.ExecContext(ctx, enableFeed, arg.ID, arg.UpdatedAt)
+	return err
+}
+
 const getFeed = `-- name: GetFeed :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE $1 = name
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, site_title, site_link, description, language, image_url, generator, download_keep, etag, last_modified, consecutive_failures, last_error, last_success_at, next_fetch_at, disabled_at, ttl, skip_hours, skip_days, fetch_interval, claimed_until FROM feeds WHERE $1 = name
 `
 
 func (q *Queries) GetFeed(ctx context.Context, name string) (Feed, error) {
@@ -80,12 +235,69 @@ func (q *Queries) GetFeed(ctx context.Context, name string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.SiteTitle,
+		&i.SiteLink,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+		&i.DownloadKeep,
+		&i.Etag,
+		&i.LastModified,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastSuccessAt,
+		&i.NextFetchAt,
+		&i.DisabledAt,
+		&i.Ttl,
+		pq.Array(&i.SkipHours),
+		pq.Array(&i.SkipDays),
+		&i.FetchInterval,
+		&i.ClaimedUntil,
+	)
+	return i, err
+}
+
+const getFeedById = `-- name: GetFeedById :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, site_title, site_link, description, language, image_url, generator, download_keep, etag, last_modified, consecutive_failures, last_error, last_success_at, next_fetch_at, disabled_at, ttl, skip_hours, skip_days, fetch_interval, claimed_until FROM feeds WHERE $1 = id
+`
+
+func (q *Queries) GetFeedById(ctx context.Context, id uuid.UUID) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedById, id)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.SiteTitle,
+		&i.SiteLink,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+		&i.DownloadKeep,
+		&i.Etag,
+		&i.LastModified,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastSuccessAt,
+		&i.NextFetchAt,
+		&i.DisabledAt,
+		&i.Ttl,
+		pq.Array(&i.SkipHours),
+		pq.Array(&i.SkipDays),
+		&i.FetchInterval,
+		&i.ClaimedUntil,
 	)
 	return i, err
 }
 
 const getFeedByURL = `-- name: GetFeedByURL :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE $1 = url
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, site_title, site_link, description, language, image_url, generator, download_keep, etag, last_modified, consecutive_failures, last_error, last_success_at, next_fetch_at, disabled_at, ttl, skip_hours, skip_days, fetch_interval, claimed_until FROM feeds WHERE $1 = url
 `
 
 func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
@@ -99,12 +311,31 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.SiteTitle,
+		&i.SiteLink,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+		&i.DownloadKeep,
+		&i.Etag,
+		&i.LastModified,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastSuccessAt,
+		&i.NextFetchAt,
+		&i.DisabledAt,
+		&i.Ttl,
+		pq.Array(&i.SkipHours),
+		pq.Array(&i.SkipDays),
+		&i.FetchInterval,
+		&i.ClaimedUntil,
 	)
 	return i, err
 }
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, site_title, site_link, description, language, image_url, generator, download_keep, etag, last_modified, consecutive_failures, last_error, last_success_at, next_fetch_at, disabled_at, ttl, skip_hours, skip_days, fetch_interval, claimed_until FROM feeds
 `
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
@@ -124,6 +355,25 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.SiteTitle,
+			&i.SiteLink,
+			&i.Description,
+			&i.Language,
+			&i.ImageUrl,
+			&i.Generator,
+			&i.DownloadKeep,
+			&i.Etag,
+			&i.LastModified,
+			&i.ConsecutiveFailures,
+			&i.LastError,
+			&i.LastSuccessAt,
+			&i.NextFetchAt,
+			&i.DisabledAt,
+			&i.Ttl,
+			pq.Array(&i.SkipHours),
+			pq.Array(&i.SkipDays),
+			&i.FetchInterval,
+			&i.ClaimedUntil,
 		); err != nil {
 			return nil, err
 		}
@@ -138,37 +388,145 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 	return items, nil
 }
 
-const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds ORDER BY last_fetched_at ASC NULLS FIRST LIMIT 1
+const recordFeedFailure = `-- name: RecordFeedFailure :exec
+UPDATE feeds
+SET updated_at = $2, last_fetched_at = $2, consecutive_failures = $3, last_error = $4, next_fetch_at = $5, disabled_at = $6, claimed_until = NULL
+WHERE $1 = id
 `
 
-func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
-	var i Feed
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		&i.Url,
-		&i.UserID,
-		&i.LastFetchedAt,
+type RecordFeedFailureParams struct {
+	ID                  uuid.UUID
+	UpdatedAt           time.Time
+	ConsecutiveFailures int32
+	LastError           sql.NullString
+	NextFetchAt         sql.NullTime
+	DisabledAt          sql.NullTime
+}
+
+func (q *Queries) RecordFeedFailure(ctx context.Context, arg RecordFeedFailureParams) error {
+	_, err := q.db.ExecContext(ctx, recordFeedFailure,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.ConsecutiveFailures,
+		arg.LastError,
+		arg.NextFetchAt,
+		arg.DisabledAt,
 	)
-	return i, err
+	return err
+}
+
+const recordFeedSuccess = `-- name: RecordFeedSuccess :exec
+UPDATE feeds
+SET updated_at = $2, last_fetched_at = $2, last_success_at = $2, consecutive_failures = 0, last_error = NULL, next_fetch_at = $3, fetch_interval = $4, disabled_at = NULL, claimed_until = NULL
+WHERE $1 = id
+`
+
+type RecordFeedSuccessParams struct {
+	ID            uuid.UUID
+	UpdatedAt     time.Time
+	NextFetchAt   sql.NullTime
+	FetchInterval sql.NullInt32
+}
+
+func (q *Queries) RecordFeedSuccess(ctx context.Context, arg RecordFeedSuccessParams) error {
+	_, err := q.db.ExecContext(ctx, recordFeedSuccess,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.NextFetchAt,
+		arg.FetchInterval,
+	)
+	return err
 }
 
-const markFeedFetched = `-- name: MarkFeedFetched :exec
+const releaseFeedClaim = `-- name: ReleaseFeedClaim :exec
 UPDATE feeds
-SET updated_at = $2, last_fetched_at = $2
+SET updated_at = $2, claimed_until = NULL
 WHERE $1 = id
 `
 
-type MarkFeedFetchedParams struct {
+type ReleaseFeedClaimParams struct {
 	ID        uuid.UUID
 	UpdatedAt time.Time
 }
 
-func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams) error {
-	_, err := q.db.ExecContext(ctx, markFeedFetched, arg.ID, arg.UpdatedAt)
+func (q *Queries) ReleaseFeedClaim(ctx context.Context, arg ReleaseFeedClaimParams) error {
+	_, err := q.db.ExecContext(ctx, releaseFeedClaim, arg.ID, arg.UpdatedAt)
+	return err
+}
+
+const setFeedDownloadKeep = `-- name: SetFeedDownloadKeep :exec
+UPDATE feeds
+SET updated_at = $2, download_keep = $3
+WHERE $1 = id
+`
+
+type SetFeedDownloadKeepParams struct {
+	ID           uuid.UUID
+	UpdatedAt    time.Time
+	DownloadKeep sql.NullInt32
+}
+
+func (q *Queries) SetFeedDownloadKeep(ctx context.Context, arg SetFeedDownloadKeepParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedDownloadKeep, arg.ID, arg.UpdatedAt, arg.DownloadKeep)
+	return err
+}
+
+const updateFeedCacheHeaders = `-- name: UpdateFeedCacheHeaders :exec
+UPDATE feeds
+SET updated_at = $2, etag = $3, last_modified = $4
+WHERE $1 = id
+`
+
+type UpdateFeedCacheHeadersParams struct {
+	ID           uuid.UUID
+	UpdatedAt    time.Time
+	Etag         sql.NullString
+	LastModified sql.NullString
+}
+
+func (q *Queries) UpdateFeedCacheHeaders(ctx context.Context, arg UpdateFeedCacheHeadersParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedCacheHeaders,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.Etag,
+		arg.LastModified,
+	)
+	return err
+}
+
+const updateFeedMetadata = `-- name: UpdateFeedMetadata :exec
+UPDATE feeds
+SET updated_at = $2, site_title = $3, site_link = $4, description = $5, language = $6, image_url = $7, generator = $8, ttl = $9, skip_hours = $10, skip_days = $11
+WHERE $1 = id
+`
+
+type UpdateFeedMetadataParams struct {
+	ID          uuid.UUID
+	UpdatedAt   time.Time
+	SiteTitle   sql.NullString
+	SiteLink    sql.NullString
+	Description sql.NullString
+	Language    sql.NullString
+	ImageUrl    sql.NullString
+	Generator   sql.NullString
+	Ttl         sql.NullInt32
+	SkipHours   []int32
+	SkipDays    []string
+}
+
+func (q *Queries) UpdateFeedMetadata(ctx context.Context, arg UpdateFeedMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedMetadata,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.SiteTitle,
+		arg.SiteLink,
+		arg.Description,
+		arg.Language,
+		arg.ImageUrl,
+		arg.Generator,
+		arg.Ttl,
+		pq.Array(arg.SkipHours),
+		pq.Array(arg.SkipDays),
+	)
 	return err
 }
@@ -11,14 +11,71 @@ import (
 	"github.com/google/uuid"
 )
 
+type Author struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Name      string
+}
+
+type Category struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Name      string
+}
+
+type Download struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	EnclosureID uuid.UUID
+	FeedID      uuid.UUID
+	Path        string
+	Size        int64
+	CompletedAt sql.NullTime
+}
+
+type Enclosure struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	PostID    uuid.UUID
+	Url       string
+	MimeType  sql.NullString
+	Length    sql.NullInt64
+	Duration  sql.NullInt32
+	Episode   sql.NullInt32
+	Season    sql.NullInt32
+}
+
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Name                string
+	Url                 string
+	UserID              uuid.UUID
+	LastFetchedAt       sql.NullTime
+	SiteTitle           sql.NullString
+	SiteLink            sql.NullString
+	Description         sql.NullString
+	Language            sql.NullString
+	ImageUrl            sql.NullString
+	Generator           sql.NullString
+	DownloadKeep        sql.NullInt32
+	Etag                sql.NullString
+	LastModified        sql.NullString
+	ConsecutiveFailures int32
+	LastError           sql.NullString
+	LastSuccessAt       sql.NullTime
+	NextFetchAt         sql.NullTime
+	DisabledAt          sql.NullTime
+	Ttl                 sql.NullInt32
+	SkipHours           []int32
+	SkipDays            []string
+	FetchInterval       sql.NullInt32
+	ClaimedUntil        sql.NullTime
 }
 
 type Feedfollow struct {
@@ -38,6 +95,45 @@ type Post struct {
 	Description sql.NullString
 	PublishedAt time.Time
 	FeedID      uuid.UUID
+	Content     sql.NullString
+	Guid        string
+	ContentHash sql.NullString
+	EditedAt    sql.NullTime
+}
+
+type Postauthor struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	PostID    uuid.UUID
+	AuthorID  uuid.UUID
+}
+
+type Postcategory struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	PostID     uuid.UUID
+	CategoryID uuid.UUID
+}
+
+type Postfeed struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	PostID    uuid.UUID
+	FeedID    uuid.UUID
+	Guid      string
+}
+
+type Postrevision struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	PostID      uuid.UUID
+	Title       sql.NullString
+	Description sql.NullString
+	Content     sql.NullString
+	ContentHash sql.NullString
 }
 
 type User struct {
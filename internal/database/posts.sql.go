@@ -11,78 +11,233 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES (
-    $1,
-    $2,
-    $3,
-    $4,
-    $5,
-    $6,
-    $7,
-    $8
-)
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+const createPosts = `-- name: CreatePosts :many
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, content, guid, content_hash)
+SELECT
+    u.id,
+    $1::timestamp,
+    $1::timestamp,
+    u.title,
+    u.url,
+    u.description,
+    u.published_at::timestamp,
+    $2::uuid,
+    NULLIF(u.content, ''),
+    u.guid,
+    u.content_hash
+FROM unnest(
+    $3::uuid[],
+    $4::text[],
+    $5::text[],
+    $6::text[],
+    $7::text[],
+    $8::text[],
+    $9::text[],
+    $10::text[]
+) AS u(id, title, url, description, published_at, content, guid, content_hash)
+ON CONFLICT (feed_id, guid) DO NOTHING
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, content, guid, content_hash, edited_at
 `
 
-type CreatePostParams struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       sql.NullString
-	Url         string
-	Description sql.NullString
-	PublishedAt time.Time
-	FeedID      uuid.UUID
+type CreatePostsParams struct {
+	CreatedAt     time.Time
+	FeedID        uuid.UUID
+	Ids           []uuid.UUID
+	Titles        []string
+	Urls          []string
+	Descriptions  []string
+	PublishedAts  []string
+	Contents      []string
+	Guids         []string
+	ContentHashes []string
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
-	row := q.db.QueryRowContext(ctx, createPost,
-		arg.ID,
+func (q *Queries) CreatePosts(ctx context.Context, arg CreatePostsParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, createPosts,
 		arg.CreatedAt,
-		arg.UpdatedAt,
-		arg.Title,
-		arg.Url,
-		arg.Description,
-		arg.PublishedAt,
 		arg.FeedID,
+		pq.Array(arg.Ids),
+		pq.Array(arg.Titles),
+		pq.Array(arg.Urls),
+		pq.Array(arg.Descriptions),
+		pq.Array(arg.PublishedAts),
+		pq.Array(arg.Contents),
+		pq.Array(arg.Guids),
+		pq.Array(arg.ContentHashes),
 	)
-	var i Post
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Title,
-		&i.Url,
-		&i.Description,
-		&i.PublishedAt,
-		&i.FeedID,
-	)
-	return i, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Guid,
+			&i.ContentHash,
+			&i.EditedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostHashesForFeed = `-- name: GetPostHashesForFeed :many
+SELECT postfeeds.guid, posts.id, posts.feed_id, posts.content_hash
+FROM postfeeds
+    INNER JOIN posts ON posts.id = postfeeds.post_id
+WHERE postfeeds.feed_id = $1 AND postfeeds.guid = ANY($2::text[])
+`
+
+type GetPostHashesForFeedParams struct {
+	FeedID uuid.UUID
+	Guids  []string
+}
+
+type GetPostHashesForFeedRow struct {
+	Guid        string
+	ID          uuid.UUID
+	FeedID      uuid.UUID
+	ContentHash sql.NullString
+}
+
+func (q *Queries) GetPostHashesForFeed(ctx context.Context, arg GetPostHashesForFeedParams) ([]GetPostHashesForFeedRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostHashesForFeed, arg.FeedID, pq.Array(arg.Guids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostHashesForFeedRow
+	for rows.Next() {
+		var i GetPostHashesForFeedRow
+		if err := rows.Scan(
+			&i.Guid,
+			&i.ID,
+			&i.FeedID,
+			&i.ContentHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsByURLs = `-- name: GetPostsByURLs :many
+SELECT DISTINCT ON (url) id, created_at, updated_at, title, url, description, published_at, feed_id, content, guid, content_hash, edited_at FROM posts
+WHERE url = ANY($1::text[])
+AND NOT EXISTS (
+    SELECT 1 FROM postfeeds
+    WHERE postfeeds.post_id = posts.id AND postfeeds.feed_id = $2
+)
+ORDER BY url, created_at
+`
+
+type GetPostsByURLsParams struct {
+	Urls   []string
+	FeedID uuid.UUID
+}
+
+func (q *Queries) GetPostsByURLs(ctx context.Context, arg GetPostsByURLsParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsByURLs, pq.Array(arg.Urls), arg.FeedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Guid,
+			&i.ContentHash,
+			&i.EditedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT
-    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id,
+SELECT DISTINCT ON (posts.published_at, posts.id)
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.content, posts.guid, posts.content_hash, posts.edited_at,
     feeds.name AS feed_name,
     users.name AS user_name
 FROM posts
-    INNER JOIN feedfollows ON posts.feed_id = feedfollows.feed_id
+    INNER JOIN postfeeds ON posts.id = postfeeds.post_id
+    INNER JOIN feedfollows ON postfeeds.feed_id = feedfollows.feed_id
     INNER JOIN users ON users.id = feedfollows.user_id
     INNER JOIN feeds ON feeds.id = feedfollows.feed_id
 WHERE
     users.name = $1
+AND ($2::text IS NULL OR EXISTS (
+    SELECT 1
+    FROM postauthors
+        INNER JOIN authors ON authors.id = postauthors.author_id
+    WHERE
+        postauthors.post_id = posts.id
+    AND lower(authors.name) = lower($2)
+))
+AND ($3::text IS NULL OR EXISTS (
+    SELECT 1
+    FROM postcategories
+        INNER JOIN categories ON categories.id = postcategories.category_id
+    WHERE
+        postcategories.post_id = posts.id
+    AND lower(categories.name) = lower($3)
+))
 ORDER BY
-    published_at DESC
-LIMIT $2
+    posts.published_at DESC, posts.id, feeds.name
+LIMIT $4
 `
 
 type GetPostsForUserParams struct {
-	Name  string
-	Limit int32
+	Name     string
+	Author   sql.NullString
+	Category sql.NullString
+	Limit    int32
 }
 
 type GetPostsForUserRow struct {
@@ -94,12 +249,21 @@ type GetPostsForUserRow struct {
 	Description sql.NullString
 	PublishedAt time.Time
 	FeedID      uuid.UUID
+	Content     sql.NullString
+	Guid        string
+	ContentHash sql.NullString
+	EditedAt    sql.NullTime
 	FeedName    string
 	UserName    string
 }
 
 func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.Name, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, getPostsForUser,
+		arg.Name,
+		arg.Author,
+		arg.Category,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -116,6 +280,10 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Content,
+			&i.Guid,
+			&i.ContentHash,
+			&i.EditedAt,
 			&i.FeedName,
 			&i.UserName,
 		); err != nil {
@@ -131,3 +299,43 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const updatePostsContent = `-- name: UpdatePostsContent :exec
+UPDATE posts
+SET
+    updated_at = $1::timestamp,
+    edited_at = $1::timestamp,
+    title = u.title,
+    description = u.description,
+    content = NULLIF(u.content, ''),
+    content_hash = u.content_hash
+FROM unnest(
+    $2::uuid[],
+    $3::text[],
+    $4::text[],
+    $5::text[],
+    $6::text[]
+) AS u(id, title, description, content, content_hash)
+WHERE posts.id = u.id
+`
+
+type UpdatePostsContentParams struct {
+	UpdatedAt     time.Time
+	Ids           []uuid.UUID
+	Titles        []string
+	Descriptions  []string
+	Contents      []string
+	ContentHashes []string
+}
+
+func (q *Queries) UpdatePostsContent(ctx context.Context, arg UpdatePostsContentParams) error {
+	_, err := q.db.ExecContext(ctx, updatePostsContent,
+		arg.UpdatedAt,
+		pq.Array(arg.Ids),
+		pq.Array(arg.Titles),
+		pq.Array(arg.Descriptions),
+		pq.Array(arg.Contents),
+		pq.Array(arg.ContentHashes),
+	)
+	return err
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+type JSONFeedAttachment struct {
+	URL               string  `json:"url"`
+	MimeType          string  `json:"mime_type"`
+	SizeInBytes       float64 `json:"size_in_bytes"`
+	DurationInSeconds float64 `json:"duration_in_seconds"`
+}
+
+type JSONFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type JSONFeedItem struct {
+	ID            json.RawMessage      `json:"id"`
+	URL           string               `json:"url"`
+	ExternalURL   string               `json:"external_url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Attachments   []JSONFeedAttachment `json:"attachments"`
+	Authors       []JSONFeedAuthor     `json:"authors"`
+	Author        *JSONFeedAuthor      `json:"author"`
+	Tags          []string             `json:"tags"`
+}
+
+type JSONFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url"`
+	FeedURL     string           `json:"feed_url"`
+	Description string           `json:"description"`
+	Icon        string           `json:"icon"`
+	Favicon     string           `json:"favicon"`
+	Language    string           `json:"language"`
+	Authors     []JSONFeedAuthor `json:"authors"`
+	Author      *JSONFeedAuthor  `json:"author"`
+	Items       []JSONFeedItem   `json:"items"`
+}
+
+// Report whether response is a JSON Feed, by Content-Type or by sniffing body
+func isJSONFeed(contentType string, body []byte) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && (mediaType == "application/feed+json" || mediaType == "application/json") {
+		return true
+	}
+	body = bytes.TrimLeft(body, " \t\r\n\ufeff")
+	return len(body) > 0 && body[0] == '{'
+}
+
+// Return item id as string (spec requires a string, some feeds publish numbers)
+func (item JSONFeedItem) id() string {
+	var id string
+	if json.Unmarshal(item.ID, &id) == nil {
+		return strings.TrimSpace(id)
+	}
+	return strings.Trim(string(item.ID), " \"")
+}
+
+// Convert JSON Feed into RSSFeed so items share the RSS post pipeline
+func (f *JSONFeed) rssFeed() *RSSFeed {
+	var (
+		rssFeed RSSFeed
+		rssItem RSSItem
+	)
+	rssFeed.Channel.Title = f.Title
+	rssFeed.Channel.Link = f.HomePageURL
+	rssFeed.Channel.Description = f.Description
+	rssFeed.Channel.Language = f.Language
+	rssFeed.Channel.Image.URL = f.Icon
+	if rssFeed.Channel.Image.URL == "" {
+		rssFeed.Channel.Image.URL = f.Favicon
+	}
+	for _, item := range f.Items {
+		rssItem = RSSItem{}
+		rssItem.Title = item.Title
+		rssItem.Link = item.URL
+		if rssItem.Link == "" {
+			rssItem.Link = item.ExternalURL
+		}
+		rssItem.GUID = item.id()
+		rssItem.Description = item.Summary
+		if rssItem.Description == "" {
+			rssItem.Description = item.ContentText
+		}
+		if rssItem.Description == "" {
+			rssItem.Description = item.ContentHTML
+		}
+		rssItem.Content = item.ContentHTML
+		if rssItem.Content == "" {
+			rssItem.Content = item.ContentText
+		}
+		for _, attachment := range item.Attachments {
+			enclosure := RSSEnclosure{URL: attachment.URL, Type: attachment.MimeType}
+			if attachment.SizeInBytes > 0 {
+				enclosure.Length = strconv.FormatInt(int64(attachment.SizeInBytes), 10)
+			}
+			if attachment.DurationInSeconds > 0 {
+				enclosure.Duration = strconv.FormatInt(int64(attachment.DurationInSeconds), 10)
+			}
+			rssItem.Enclosure = append(rssItem.Enclosure, enclosure)
+		}
+		// JSON Feed 1.0 has a single author, and items inherit feed authors
+		authors := item.Authors
+		if item.Author != nil {
+			authors = append(authors, *item.Author)
+		}
+		if len(authors) == 0 {
+			authors = f.Authors
+			if f.Author != nil {
+				authors = append(authors, *f.Author)
+			}
+		}
+		for _, author := range authors {
+			rssItem.Author = append(rssItem.Author, author.Name)
+		}
+		rssItem.Category = item.Tags
+		rssItem.PubDate = item.DatePublished
+		if rssItem.PubDate == "" {
+			rssItem.PubDate = item.DateModified
+		}
+		rssFeed.Channel.Item = append(rssFeed.Channel.Item, rssItem)
+	}
+	return &rssFeed
+}
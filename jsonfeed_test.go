@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestJSONFeedRSSFeed(t *testing.T) {
+	tests := []struct {
+		name     string
+		feed     string
+		link     string
+		guid     string
+		desc     string
+		content  string
+		pubDate  string
+		authors  []string
+		category []string
+	}{
+		{
+			name:     "string id and 1.1 authors",
+			feed:     `{"version": "https://jsonfeed.org/version/1.1", "title": "t", "items": [{"id": "a1", "url": "https://example.com/1", "summary": "short", "content_html": "<p>long</p>", "date_published": "2024-03-01T10:00:00Z", "authors": [{"name": "Ann"}, {"name": "Bob"}], "tags": ["go"]}]}`,
+			link:     "https://example.com/1",
+			guid:     "a1",
+			desc:     "short",
+			content:  "<p>long</p>",
+			pubDate:  "2024-03-01T10:00:00Z",
+			authors:  []string{"Ann", "Bob"},
+			category: []string{"go"},
+		},
+		{
+			name:    "numeric id and 1.0 author",
+			feed:    `{"version": "https://jsonfeed.org/version/1", "title": "t", "items": [{"id": 42, "url": "https://example.com/2", "content_text": "plain", "date_modified": "2024-03-02T10:00:00Z", "author": {"name": "Cat"}}]}`,
+			link:    "https://example.com/2",
+			guid:    "42",
+			desc:    "plain",
+			content: "plain",
+			pubDate: "2024-03-02T10:00:00Z",
+			authors: []string{"Cat"},
+		},
+		{
+			name:    "external url and feed authors",
+			feed:    `{"version": "https://jsonfeed.org/version/1.1", "title": "t", "authors": [{"name": "Dee"}], "author": {"name": "Eve"}, "items": [{"id": " x ", "external_url": "https://other.example.com/3", "content_html": "<b>html</b>"}]}`,
+			link:    "https://other.example.com/3",
+			guid:    "x",
+			desc:    "<b>html</b>",
+			content: "<b>html</b>",
+			authors: []string{"Dee", "Eve"},
+		},
+	}
+	for _, tt := range tests {
+		var jsonFeed JSONFeed
+		err := json.Unmarshal([]byte(tt.feed), &jsonFeed)
+		if err != nil {
+			t.Errorf("%s: json.Unmarshal error: %v", tt.name, err)
+			continue
+		}
+		rssFeed := jsonFeed.rssFeed()
+		if len(rssFeed.Channel.Item) != 1 {
+			t.Errorf("%s: got %d items, want 1", tt.name, len(rssFeed.Channel.Item))
+			continue
+		}
+		rssItem := rssFeed.Channel.Item[0]
+		if rssItem.Link != tt.link || rssItem.GUID != tt.guid || rssItem.Description != tt.desc || rssItem.Content != tt.content || rssItem.PubDate != tt.pubDate {
+			t.Errorf("%s: item = link %q, guid %q, description %q, content %q, date %q", tt.name, rssItem.Link, rssItem.GUID, rssItem.Description, rssItem.Content, rssItem.PubDate)
+		}
+		if !slices.Equal(rssItem.Author, tt.authors) || !slices.Equal(rssItem.Category, tt.category) {
+			t.Errorf("%s: authors %q, categories %q, want %q, %q", tt.name, rssItem.Author, rssItem.Category, tt.authors, tt.category)
+		}
+	}
+}
+
+func TestIsJSONFeed(t *testing.T) {
+	tests := []struct {
+		contentType string
+		body        string
+		want        bool
+	}{
+		{"application/feed+json", "", true},
+		{"application/json; charset=utf-8", "", true},
+		{"text/plain", "\ufeff  {\"version\": \"\"}", true},
+		{"application/rss+xml", "<rss></rss>", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got := isJSONFeed(tt.contentType, []byte(tt.body))
+		if got != tt.want {
+			t.Errorf("isJSONFeed(%q, %q) = %v, want %v", tt.contentType, tt.body, got, tt.want)
+		}
+	}
+}
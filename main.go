@@ -1,15 +1,20 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"html"
 	"io"
 	"net/http"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dragonicorn/gator/internal/config"
@@ -21,6 +26,7 @@ import (
 
 type state struct {
 	db     *database.Queries
+	sqlDB  *sql.DB
 	config *config.Config
 }
 
@@ -150,85 +156,414 @@ func handlerusers(s *state, cmd command) error {
 
 func handleragg(s *state, cmd command) error {
 	var (
-		// ctx context.Context = context.Background()
-		err error
+		ctx      context.Context
+		cancel   context.CancelFunc
+		download bool
+		interval time.Duration
+		once     bool
+		opts     scrapeOptions
+		summary  scrapeSummary
+		err      error
 		// feed *RSSFeed
 		ticker *time.Ticker
 	)
-	if len(cmd.args) == 0 {
-		fmt.Printf("%s command requires feed update interval\n", cmd.name)
-		return fmt.Errorf("%s command requires feed update interval\n", cmd.name)
+	opts.workers = s.config.ScrapeWorkers()
+	opts.hostWorkers = s.config.ScrapeHostWorkers()
+	opts.timeout = s.config.ScrapeTimeout()
+	for i := 0; i < len(cmd.args); i++ {
+		switch cmd.args[i] {
+		case "--download":
+			download = true
+		case "--once":
+			once = true
+		case "--workers", "--host-workers":
+			if i+1 == len(cmd.args) {
+				return fmt.Errorf("%s command option %s requires a number\n", cmd.name, cmd.args[i])
+			}
+			n, err := strconv.Atoi(cmd.args[i+1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("%s command option %s requires a positive integer\n", cmd.name, cmd.args[i])
+			}
+			if cmd.args[i] == "--workers" {
+				opts.workers = n
+			} else {
+				opts.hostWorkers = n
+			}
+			i++
+		case "--timeout":
+			if i+1 == len(cmd.args) {
+				return fmt.Errorf("%s command option %s requires a duration\n", cmd.name, cmd.args[i])
+			}
+			opts.timeout, err = time.ParseDuration(cmd.args[i+1])
+			if err != nil || opts.timeout <= 0 {
+				return fmt.Errorf("%s command option %s requires a positive duration\n", cmd.name, cmd.args[i])
+			}
+			i++
+		default:
+			if i > 0 || strings.HasPrefix(cmd.args[i], "--") {
+				fmt.Printf("%s command option '%s' not recognised\n", cmd.name, cmd.args[i])
+				return fmt.Errorf("%s command option '%s' not recognised\n", cmd.name, cmd.args[i])
+			}
+			interval, err = time.ParseDuration(cmd.args[i])
+			// _, err = fetchFeed(ctx, "https://www.wagslane.dev/index.xml")
+			if err != nil {
+				fmt.Println("Error determining feed update interval")
+				return fmt.Errorf("error %v determining feed update interval\n", err)
+			}
+		}
 	}
-	interval, err := time.ParseDuration(cmd.args[0])
-	// _, err = fetchFeed(ctx, "https://www.wagslane.dev/index.xml")
-	if err != nil {
-		fmt.Println("Error determining feed update interval")
-		return fmt.Errorf("error %v determining feed update interval\n", err)
+	if interval <= 0 && !once {
+		fmt.Printf("%s command requires feed update interval (or --once)\n", cmd.name)
+		return fmt.Errorf("%s command requires feed update interval (or --once)\n", cmd.name)
+	}
+	if once {
+		fmt.Println("Collecting feeds which are due once")
+	} else {
+		fmt.Printf("Collecting feeds which are due every %s\n", interval)
+	}
+	fmt.Printf("Fetching up to %d feeds at a time (%d per host), each within %s\n", opts.workers, opts.hostWorkers, opts.timeout)
+	if download {
+		fmt.Printf("Downloading enclosures of feeds followed by '%s' to %s\n", s.config.UserName, s.config.DownloadPath())
+	}
+	// Stop on SIGINT or SIGTERM once feeds being stored are finished
+	ctx, cancel = signalContext()
+	defer cancel()
+	if once {
+		// Refresh due feeds and wait for all work to finish before exiting,
+		// with non-zero exit status if any feed failed (e.g. for cron)
+		summary = scrapeFeeds(ctx, s, opts)
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s command interrupted\n", cmd.name)
+		}
+		if download {
+			err = downloadFollowedFeeds(ctx, s, s.config.UserName)
+			if err != nil {
+				fmt.Printf("Error downloading enclosures: %v\n", err)
+				return fmt.Errorf("error %v downloading enclosures\n", err)
+			}
+		}
+		if summary.failed > 0 {
+			return fmt.Errorf("%d feeds failed to fetch\n", summary.failed)
+		}
+		return nil
 	}
-	fmt.Printf("Collecting feeds every %s\n", interval)
 	ticker = time.NewTicker(interval)
-	for ; ; <-ticker.C {
-		scrapeFeeds(s)
+	defer ticker.Stop()
+	for {
+		scrapeFeeds(ctx, s, opts)
+		if download && ctx.Err() == nil {
+			err = downloadFollowedFeeds(ctx, s, s.config.UserName)
+			if err != nil {
+				fmt.Printf("Error downloading enclosures: %v\n", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			fmt.Println("Stopped collecting feeds")
+			return nil
+		case <-ticker.C:
+		}
 	}
-	return nil
 }
 
-func scrapeFeeds(s *state) error {
+// Fetch every enabled feed which is due to be fetched, concurrently
+func scrapeFeeds(ctx context.Context, s *state, opts scrapeOptions) scrapeSummary {
+	var summary scrapeSummary = scrapeConcurrently(ctx, s, opts)
+	if summary.fetched == 0 {
+		fmt.Println("  No enabled feeds due to be fetched")
+		return summary
+	}
+	fmt.Printf("  %d feeds fetched, %d failed, %d posts added\n", summary.fetched-summary.failed, summary.failed, summary.added)
+	return summary
+}
+
+// Fetch feed and store its new posts, returning number of posts added.
+// Cancelling ctx aborts the fetch, but once fetched the feed is stored in
+// full, in a single transaction so that it is never left partly stored.
+func scrapeFeed(ctx context.Context, s *state, dbFeed database.Feed) (int, error) {
 	var (
-		ctx          context.Context = context.Background()
-		dbFeed       database.Feed
-		dbParams     database.MarkFeedFetchedParams
-		dbPost       database.Post
-		dbPostParams database.CreatePostParams
+		dbCtx     context.Context = context.WithoutCancel(ctx)
+		err       error
+		fetchedAt time.Time
+		header    http.Header
+		inserted  int
+		rssFeed   *RSSFeed
+	)
+	fmt.Printf("  Scraping feed %s...\n", dbFeed.Name)
+	fetchedAt = time.Now()
+	rssFeed, header, err = fetchFeed(ctx, dbFeed.Url, dbFeed.Etag.String, dbFeed.LastModified.String)
+	if errors.Is(err, errNotModified) {
+		fmt.Printf("  Feed %s not modified since last fetch\n", dbFeed.Name)
+		return 0, recordFeedSuccess(dbCtx, s, dbFeed, dbFeed.FetchInterval, nextFetchTime(fetchedAt, dbFeed.FetchInterval, dbFeed.Ttl, dbFeed.SkipHours, dbFeed.SkipDays))
+	}
+	if err != nil {
+		fmt.Printf("  Error fetching feed %s: %v\n", dbFeed.Name, err)
+		// a fetch aborted by shutdown is not a failure of the feed
+		if errors.Is(ctx.Err(), context.Canceled) {
+			errRelease := releaseFeedClaim(dbCtx, s, dbFeed)
+			if errRelease != nil {
+				return 0, errRelease
+			}
+			return 0, fmt.Errorf("fetch of feed %s cancelled\n", dbFeed.Name)
+		}
+		errRecord := recordFeedFailure(dbCtx, s, dbFeed, err, header)
+		if errRecord != nil {
+			return 0, errRecord
+		}
+		return 0, fmt.Errorf("error %v fetching feed\n", err)
+	}
+
+	inserted, err = storeFeed(dbCtx, s, dbFeed, rssFeed, header, fetchedAt)
+	if err != nil {
+		// nothing was stored, so back off as after a failed fetch
+		fmt.Printf("  Error storing feed %s: %v", dbFeed.Name, err)
+		errRecord := recordFeedFailure(dbCtx, s, dbFeed, err, nil)
+		if errRecord != nil {
+			return 0, errRecord
+		}
+		return 0, err
+	}
+	return inserted, nil
+}
+
+// Store fetched feed and its new posts in a transaction, returning number of
+// posts added, and schedule its next fetch
+func storeFeed(ctx context.Context, s *state, dbFeed database.Feed, rssFeed *RSSFeed, header http.Header, fetchedAt time.Time) (int, error) {
+	var (
+		dbMetaParams database.UpdateFeedMetadataParams
+		dbHdrParams  database.UpdateFeedCacheHeadersParams
+		dbHashParams database.GetPostHashesForFeedParams
+		dbPostParams database.CreatePostsParams
+		dbURLParams  database.GetPostsByURLsParams
+		dbLinkParams database.CreatePostFeedsParams
+		dbPFParams   database.CreatePostFeedsParams
+		dbEditParams database.UpdatePostsContentParams
+		dbHashes     []database.GetPostHashesForFeedRow
+		dbLinked     []uuid.UUID
+		dbPosts      []database.Post
+		edited       int
 		err          error
-		pd           time.Time
-		rssFeed      *RSSFeed
+		fallbacks    []string
+		inserted     int
+		items        map[string]RSSItem = make(map[string]RSSItem)
+		linked       int
+		postIDs      []uuid.UUID
+		postItems    []RSSItem
+		published    []time.Time
 		rssItem      RSSItem
+		skipped      int
+		tx           *sql.Tx
+		txState      state = *s
+		urls         []string
 	)
-	dbFeed, err = s.db.GetNextFeedToFetch(ctx)
+	// Store feed in a transaction, using state whose queries run in it
+	tx, err = s.sqlDB.BeginTx(ctx, nil)
 	if err != nil {
-		fmt.Printf("Unable to get next feed to fetch from database\n")
-		return fmt.Errorf("Unable to get next feed to fetch from database\n")
+		return 0, fmt.Errorf("begin transaction database error: %v\n", err)
 	}
-	fmt.Printf("  Scraping feed %s...\n", dbFeed.Name)
-	dbParams.ID = dbFeed.ID
-	dbParams.UpdatedAt = time.Now()
-	err = s.db.MarkFeedFetched(ctx, dbParams)
+	defer tx.Rollback()
+	txState.db = s.db.WithTx(tx)
+
+	// Update feed with channel metadata
+	dbMetaParams.ID = dbFeed.ID
+	dbMetaParams.UpdatedAt = time.Now()
+	dbMetaParams.SiteTitle = nullString(rssFeed.Channel.Title)
+	dbMetaParams.SiteLink = nullString(rssFeed.Channel.Link)
+	dbMetaParams.Description = nullString(rssFeed.Channel.Description)
+	dbMetaParams.Language = nullString(rssFeed.Channel.Language)
+	dbMetaParams.ImageUrl = nullString(rssFeed.Channel.Image.URL)
+	dbMetaParams.Generator = nullString(rssFeed.Channel.Generator)
+	dbMetaParams.Ttl = feedTTL(rssFeed)
+	dbMetaParams.SkipHours = feedSkipHours(rssFeed)
+	dbMetaParams.SkipDays = feedSkipDays(rssFeed)
+	err = txState.db.UpdateFeedMetadata(ctx, dbMetaParams)
 	if err != nil {
-		return fmt.Errorf("mark feed fetched database update query error: %v\n", err)
+		return 0, fmt.Errorf("update feed metadata database update query error: %v\n", err)
+	}
+
+	// Find items whose guid is already in database for this feed
+	dbHashParams.FeedID = dbFeed.ID
+	for _, rssItem = range rssFeed.Channel.Item {
+		dbHashParams.Guids = append(dbHashParams.Guids, itemGUID(rssItem))
 	}
-	rssFeed, err = fetchFeed(ctx, dbFeed.Url)
+	dbHashes, err = txState.db.GetPostHashesForFeed(ctx, dbHashParams)
 	if err != nil {
-		return fmt.Errorf("error %v fetching feed\n", err)
+		fmt.Printf("rssItem database select query error: %v\n", err)
+		return 0, fmt.Errorf("rssItem database select query error: %v\n", err)
 	}
+	existing := make(map[string]database.GetPostHashesForFeedRow)
+	for _, dbHash := range dbHashes {
+		existing[dbHash.Guid] = dbHash
+	}
+	// Collect new posts, skipping items repeated within the feed and
+	// updating posts whose content has been edited since last fetch
+	dbPostParams.CreatedAt = time.Now()
+	dbPostParams.FeedID = dbFeed.ID
 	for _, rssItem = range rssFeed.Channel.Item {
-		// Create new post in database
-		dbPostParams.ID = uuid.New()
-		dbPostParams.CreatedAt = time.Now()
-		dbPostParams.UpdatedAt = dbPostParams.CreatedAt
-		dbPostParams.Title.String = rssItem.Title
-		dbPostParams.Title.Valid = true
-		dbPostParams.Url = rssItem.Link
-		dbPostParams.Description.String = html.UnescapeString(rssItem.Description)
-		dbPostParams.Description.Valid = true
+		guid := itemGUID(rssItem)
+		if _, ok := items[guid]; ok {
+			skipped++
+			continue
+		}
 		// fmt.Printf("PubDate = %s\n", rssItem.PubDate)
-		pd, err = time.Parse("Mon, 02 Jan 2006 15:04:05 -0700", rssItem.PubDate)
-		if err != nil {
-			fmt.Printf("rssItem.PubDate parsing error: %v\n", err)
-			return fmt.Errorf("rssItem.PubDate parsing error: %v\n", err)
+		pd, dateErr := parsePubDate(rssItem.PubDate)
+		if dateErr == nil {
+			// dates of all items, not only new posts, show how often the
+			// feed publishes
+			published = append(published, pd)
 		}
-		// fmt.Printf("pd/PublishedAt = %v\n", pd)
-		dbPostParams.PublishedAt = pd
-		dbPostParams.FeedID = dbFeed.ID
-		dbPost, err = s.db.CreatePost(ctx, dbPostParams)
-		if err != nil {
-			if err.Error() == "pq: duplicate key value violates unique constraint \"posts_url_key\"" {
-				fmt.Printf("ignoring rssItem url already in database...\n")
+		description := html.UnescapeString(rssItem.Description)
+		// full article body, when feed provides more than a teaser description
+		content := strings.TrimSpace(rssItem.Content)
+		hash := postContentHash(rssItem.Title, description, content)
+		if dbHash, ok := existing[guid]; ok {
+			items[guid] = rssItem
+			// only the feed which first published a post may edit it
+			if dbHash.FeedID != dbFeed.ID || dbHash.ContentHash.String == hash {
+				skipped++
 				continue
 			}
+			dbEditParams.Ids = append(dbEditParams.Ids, dbHash.ID)
+			dbEditParams.Titles = append(dbEditParams.Titles, rssItem.Title)
+			dbEditParams.Descriptions = append(dbEditParams.Descriptions, description)
+			dbEditParams.Contents = append(dbEditParams.Contents, content)
+			dbEditParams.ContentHashes = append(dbEditParams.ContentHashes, hash)
+			fmt.Printf("post %s edited, updated in database\n", rssItem.Link)
+			continue
+		}
+		items[guid] = rssItem
+		if dateErr != nil {
+			// fall back to fetch time rather than dropping the rest of the feed
+			fmt.Printf("rssItem.PubDate parsing error: %v (using fetch time)\n", dateErr)
+			pd = fetchedAt
+			fallbacks = append(fallbacks, rssItem.Link)
+		}
+		dbPostParams.Ids = append(dbPostParams.Ids, uuid.New())
+		dbPostParams.Titles = append(dbPostParams.Titles, rssItem.Title)
+		dbPostParams.Urls = append(dbPostParams.Urls, rssItem.Link)
+		dbPostParams.Descriptions = append(dbPostParams.Descriptions, description)
+		dbPostParams.PublishedAts = append(dbPostParams.PublishedAts, pd.Format(time.RFC3339Nano))
+		dbPostParams.Contents = append(dbPostParams.Contents, content)
+		dbPostParams.Guids = append(dbPostParams.Guids, guid)
+		dbPostParams.ContentHashes = append(dbPostParams.ContentHashes, hash)
+		if rssItem.Link != "" {
+			urls = append(urls, rssItem.Link)
+		}
+	}
+
+	// Link feed to posts already in database from other feeds, by url (a
+	// url reused by this feed for a new guid is a new post)
+	dbLinkParams.CreatedAt = dbPostParams.CreatedAt
+	dbLinkParams.FeedID = dbFeed.ID
+	if len(urls) > 0 {
+		dbURLParams.Urls = urls
+		dbURLParams.FeedID = dbFeed.ID
+		dbPosts, err = txState.db.GetPostsByURLs(ctx, dbURLParams)
+		if err != nil {
+			fmt.Printf("rssItem database select query error: %v\n", err)
+			return 0, fmt.Errorf("rssItem database select query error: %v\n", err)
+		}
+	}
+	postURLs := make(map[uuid.UUID]string)
+	for _, dbPost := range dbPosts {
+		i := slices.Index(dbPostParams.Urls, dbPost.Url)
+		dbLinkParams.Ids = append(dbLinkParams.Ids, uuid.New())
+		dbLinkParams.PostIds = append(dbLinkParams.PostIds, dbPost.ID)
+		dbLinkParams.Guids = append(dbLinkParams.Guids, dbPostParams.Guids[i])
+		postURLs[dbPost.ID] = dbPost.Url
+		// remove from posts to create
+		dbPostParams.Ids = slices.Delete(dbPostParams.Ids, i, i+1)
+		dbPostParams.Titles = slices.Delete(dbPostParams.Titles, i, i+1)
+		dbPostParams.Urls = slices.Delete(dbPostParams.Urls, i, i+1)
+		dbPostParams.Descriptions = slices.Delete(dbPostParams.Descriptions, i, i+1)
+		dbPostParams.PublishedAts = slices.Delete(dbPostParams.PublishedAts, i, i+1)
+		dbPostParams.Contents = slices.Delete(dbPostParams.Contents, i, i+1)
+		dbPostParams.Guids = slices.Delete(dbPostParams.Guids, i, i+1)
+		dbPostParams.ContentHashes = slices.Delete(dbPostParams.ContentHashes, i, i+1)
+	}
+	if len(dbLinkParams.Ids) > 0 {
+		dbLinked, err = txState.db.CreatePostFeeds(ctx, dbLinkParams)
+		if err != nil {
+			fmt.Printf("rssItem database insert query error: %v\n", err)
+			return 0, fmt.Errorf("rssItem database insert query error: %v\n", err)
+		}
+		// others already linked to this feed under another guid
+		skipped += len(dbLinkParams.Ids) - len(dbLinked)
+		linked = len(dbLinked)
+		for _, postID := range dbLinked {
+			fmt.Printf("post %s already in database, linked to feed %s\n", postURLs[postID], dbFeed.Name)
+		}
+	}
+
+	// Create new posts in database and link them to feed
+	dbPosts = nil
+	if len(dbPostParams.Ids) > 0 {
+		dbPosts, err = txState.db.CreatePosts(ctx, dbPostParams)
+		if err != nil {
+			fmt.Printf("rssItem database insert query error: %v\n", err)
+			return 0, fmt.Errorf("rssItem database insert query error: %v\n", err)
+		}
+		skipped += len(dbPostParams.Ids) - len(dbPosts)
+	}
+	dbPFParams.CreatedAt = dbPostParams.CreatedAt
+	dbPFParams.FeedID = dbFeed.ID
+	for _, dbPost := range dbPosts {
+		dbPFParams.Ids = append(dbPFParams.Ids, uuid.New())
+		dbPFParams.PostIds = append(dbPFParams.PostIds, dbPost.ID)
+		dbPFParams.Guids = append(dbPFParams.Guids, dbPost.Guid)
+	}
+	if len(dbPFParams.Ids) > 0 {
+		_, err = txState.db.CreatePostFeeds(ctx, dbPFParams)
+		if err != nil {
 			fmt.Printf("rssItem database insert query error: %v\n", err)
-			return fmt.Errorf("rssItem database insert query error: %v\n", err)
+			return 0, fmt.Errorf("rssItem database insert query error: %v\n", err)
 		}
+	}
+	for _, dbPost := range dbPosts {
+		postIDs = append(postIDs, dbPost.ID)
+		postItems = append(postItems, items[dbPost.Guid])
+	}
+	err = createEnclosures(ctx, &txState, postIDs, postItems)
+	if err != nil {
+		fmt.Printf("rssItem enclosure database insert query error: %v\n", err)
+		return 0, fmt.Errorf("rssItem enclosure database insert query error: %v\n", err)
+	}
+	err = createAuthorsAndCategories(ctx, &txState, postIDs, postItems)
+	if err != nil {
+		fmt.Printf("rssItem author/category database insert query error: %v\n", err)
+		return 0, fmt.Errorf("rssItem author/category database insert query error: %v\n", err)
+	}
+	// Update edited posts, keeping their previous content as revisions
+	dbEditParams.UpdatedAt = time.Now()
+	err = revisePosts(ctx, &txState, dbEditParams)
+	if err != nil {
+		return 0, err
+	}
+	edited = len(dbEditParams.Ids)
+
+	// Schedule next fetch according to how often feed publishes and its hints
+	interval := adaptInterval(published, fetchedAt, dbFeed.FetchInterval)
+	err = recordFeedSuccess(ctx, &txState, dbFeed, interval, nextFetchTime(fetchedAt, interval, dbMetaParams.Ttl, dbMetaParams.SkipHours, dbMetaParams.SkipDays))
+	if err != nil {
+		return 0, err
+	}
+	// Save validators with posts, so that a failed scrape is not skipped as
+	// unmodified on the next fetch
+	dbHdrParams.ID = dbFeed.ID
+	dbHdrParams.UpdatedAt = time.Now()
+	dbHdrParams.Etag = nullString(header.Get("ETag"))
+	dbHdrParams.LastModified = nullString(header.Get("Last-Modified"))
+	err = txState.db.UpdateFeedCacheHeaders(ctx, dbHdrParams)
+	if err != nil {
+		return 0, fmt.Errorf("update feed cache headers database update query error: %v\n", err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("commit transaction database error: %v\n", err)
+	}
+
+	inserted = len(dbPosts)
+	for _, dbPost := range dbPosts {
 		fmt.Println("Post database record added to database:")
 		fmt.Printf("\tID = %v\n", dbPost.ID)
 		fmt.Printf("\tCreated At = %v\n", dbPost.CreatedAt)
@@ -238,32 +573,111 @@ func scrapeFeeds(s *state) error {
 		}
 		fmt.Printf("\tUrl = %s\n", dbPost.Url)
 		if dbPost.Description.Valid {
-			fmt.Printf("\tDescription = %s\n", dbPost.Description.String)
+			fmt.Printf("\tDescription =\n%s\n", renderPostBody(dbPost.Description.String))
 		}
 		fmt.Printf("\tPublication Date = %s\n", dbPost.PublishedAt)
 		fmt.Printf("\tFeed ID = %s\n", dbPost.FeedID)
-		// break
+		fmt.Printf("\tGUID = %s\n", dbPost.Guid)
 	}
-	return nil
+	fmt.Printf("  %d posts added, %d posts edited, %d posts linked from other feeds, %d posts already in database for feed %s\n", inserted, edited, linked, skipped, dbFeed.Name)
+	if len(fallbacks) > 0 {
+		fmt.Printf("  %d items in feed %s used fetch time as publication date:\n", len(fallbacks), dbFeed.Name)
+		for _, link := range fallbacks {
+			fmt.Printf("\t%s\n", link)
+		}
+	}
+	return inserted, nil
 }
 
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	// itunes:title and media:title must precede title so that they are not
+	// decoded as title, and likewise media:description
+	ITunesTitle      string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd title"`
+	MediaTitle       string `xml:"http://search.yahoo.com/mrss/ title"`
+	MediaDescription string `xml:"http://search.yahoo.com/mrss/ description"`
+	Title            string `xml:"title"`
+	// atom:link must not be mistaken for item link
+	AtomLink    []AtomLink `xml:"http://www.w3.org/2005/Atom link"`
+	Link        string     `xml:"link"`
+	Description string     `xml:"description"`
+	PubDate     string     `xml:"pubDate"`
+	GUID        string     `xml:"guid"`
+	DCDate      string     `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Content     string     `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	// podcast and media attachments
+	Enclosure      []RSSEnclosure `xml:"enclosure"`
+	MediaContent   []RSSEnclosure `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaGroup     []RSSEnclosure `xml:"http://search.yahoo.com/mrss/ group>content"`
+	ITunesDuration string         `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	ITunesEpisode  string         `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd episode"`
+	ITunesSeason   string         `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd season"`
+	// itunes:author must precede author so that it is not decoded as author
+	ITunesAuthor string   `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+	Author       []string `xml:"author"`
+	DCCreator    []string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Category     []string `xml:"category"`
+	DCSubject    []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+}
+
+// Return identifier used to deduplicate item within its feed, falling back
+// to link (and then title) for items without a guid
+func itemGUID(rssItem RSSItem) string {
+	var guid string = strings.TrimSpace(rssItem.GUID)
+	if guid == "" {
+		guid = strings.TrimSpace(rssItem.Link)
+	}
+	if guid == "" {
+		guid = strings.TrimSpace(rssItem.Title)
+	}
+	return guid
 }
 
 type RSSFeed struct {
 	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
+		// itunes:title must precede title so that it is not decoded as title
+		ITunesTitle string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd title"`
+		Title       string `xml:"title"`
+		// atom:link (e.g. rel="self") must not be mistaken for channel link
+		AtomLink    []AtomLink `xml:"http://www.w3.org/2005/Atom link"`
+		Link        string     `xml:"link"`
+		Description string     `xml:"description"`
+		Language    string     `xml:"language"`
+		Generator   string     `xml:"generator"`
+		// itunes:image must precede image so that it is not decoded as image
+		ITunesImage struct {
+			Href string `xml:"href,attr"`
+		} `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+		Image struct {
+			URL string `xml:"url"`
+		} `xml:"image"`
+		// hints on how often feed should be fetched
+		TTL             string    `xml:"ttl"`
+		SkipHours       []string  `xml:"skipHours>hour"`
+		SkipDays        []string  `xml:"skipDays>day"`
+		UpdatePeriod    string    `xml:"http://purl.org/rss/1.0/modules/syndication/ updatePeriod"`
+		UpdateFrequency string    `xml:"http://purl.org/rss/1.0/modules/syndication/ updateFrequency"`
+		Item            []RSSItem `xml:"item"`
 	} `xml:"channel"`
 }
 
-func getURL(ctx context.Context, url string) ([]byte, error) {
+// Return valid sql.NullString for non-empty string, otherwise NULL
+func nullString(s string) sql.NullString {
+	s = strings.TrimSpace(s)
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// Returned by getURLConditional when the server reports that the resource
+// has not changed since the validators were received
+var errNotModified = errors.New("not modified")
+
+func getURL(ctx context.Context, url string) ([]byte, http.Header, error) {
+	return getURLConditional(ctx, url, "", "")
+}
+
+// Get URL, sending ETag and Last-Modified validators from a previous response
+// (if not empty) so that the server can respond 304 Not Modified instead of
+// sending the body again, in which case errNotModified is returned
+func getURLConditional(ctx context.Context, url string, etag string, lastModified string) ([]byte, http.Header, error) {
 	var (
 		body   []byte
 		client http.Client
@@ -273,64 +687,137 @@ func getURL(ctx context.Context, url string) ([]byte, error) {
 	)
 	req, err = http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	req.Header.Set("User-Agent", "gator")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
 	resp, err = client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 	// fmt.Printf("Status: %s\n", resp.Status)
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, resp.Header, errNotModified
+	}
 	if resp.StatusCode > 299 {
 		fmt.Printf("Unable to fetch Feed %s\n", url)
-		return nil, err
+		return nil, resp.Header, fmt.Errorf("unable to fetch %s: %s", url, resp.Status)
 	}
 	body, err = io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return body, nil
+	return body, resp.Header, nil
 }
 
-func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
+// Return root element of feed XML (e.g. rss or feed), leaving decoder
+// positioned to decode the rest of the element
+func feedRoot(decoder *xml.Decoder) (xml.StartElement, error) {
+	var (
+		token xml.Token
+		err   error
+	)
+	for {
+		token, err = decoder.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if element, ok := token.(xml.StartElement); ok {
+			return element, nil
+		}
+	}
+}
+
+// Fetch and parse feed, sending validators from the previous fetch. Returns
+// the response header, holding the validators to send on the next fetch, or
+// errNotModified if the feed has not changed since the previous fetch.
+func fetchFeed(ctx context.Context, feedURL string, etag string, lastModified string) (*RSSFeed, http.Header, error) {
 	var (
 		body []byte
 		// client  http.Client
 		// req     *http.Request
 		// resp    *http.Response
-		rssFeed RSSFeed
-		err     error
+		atomFeed AtomFeed
+		decoder  *xml.Decoder
+		header   http.Header
+		jsonFeed JSONFeed
+		rdfFeed  RDFFeed
+		root     xml.StartElement
+		rssFeed  RSSFeed
+		err      error
 	)
 	// req, err = http.NewRequestWithContext(ctx, "GET", feedURL, nil)
 	// if err != nil {
-	// 	return nil, err
+	// 	return nil, nil, err
 	// }
 	// req.Header.Set("User-Agent", "gator")
 	// resp, err = client.Do(req)
 	// if err != nil {
-	// 	return nil, err
+	// 	return nil, nil, err
 	// }
 	// defer resp.Body.Close()
 	// // fmt.Printf("Status: %s\n", resp.Status)
 	// if resp.StatusCode > 299 {
 	// 	fmt.Printf("Unable to fetch Feed %s\n", feedURL)
-	// 	return nil, err
+	// 	return nil, nil, err
 	// }
 	// body, err = io.ReadAll(resp.Body)
 	// if err != nil {
-	// 	return nil, err
+	// 	return nil, nil, err
 	// }
-	body, err = getURL(ctx, feedURL)
+	body, header, err = getURLConditional(ctx, feedURL, etag, lastModified)
+	if errors.Is(err, errNotModified) {
+		return nil, header, err
+	}
 	if err != nil {
 		fmt.Printf("Error getting URL")
-		return nil, err
+		return nil, nil, err
 	}
 	// fmt.Println(string(body))
-	err = xml.Unmarshal(body, &rssFeed)
+	if isJSONFeed(header.Get("Content-Type"), body) {
+		err = json.Unmarshal(bytes.TrimPrefix(body, []byte("\ufeff")), &jsonFeed)
+		if err != nil {
+			fmt.Printf("Error unmarshaling JSON feed")
+			return nil, nil, err
+		}
+		return jsonFeed.rssFeed(), header, nil
+	}
+	decoder, err = newFeedDecoder(body, header.Get("Content-Type"))
+	if err != nil {
+		fmt.Printf("Error decoding feed charset")
+		return nil, nil, err
+	}
+	root, err = feedRoot(decoder)
+	if err != nil {
+		fmt.Printf("Error reading feed XML")
+		return nil, nil, err
+	}
+	switch root.Name.Local {
+	case "feed":
+		err = decoder.DecodeElement(&atomFeed, &root)
+		if err != nil {
+			fmt.Printf("Error unmarshaling Atom feed XML")
+			return nil, nil, err
+		}
+		return atomFeed.rssFeed(), header, nil
+	case "RDF":
+		err = decoder.DecodeElement(&rdfFeed, &root)
+		if err != nil {
+			fmt.Printf("Error unmarshaling RDF feed XML")
+			return nil, nil, err
+		}
+		return rdfFeed.rssFeed(), header, nil
+	}
+	err = decoder.DecodeElement(&rssFeed, &root)
 	if err != nil {
 		fmt.Printf("Error unmarshaling feed XML")
-		return nil, err
+		return nil, nil, err
 	}
 	// convert XML escaped entities into normal entities
 	rssFeed.Channel.Title = html.UnescapeString(rssFeed.Channel.Title)
@@ -349,8 +836,17 @@ func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 		// fmt.Printf("\tDescription: %s\n", v.Description)
 		break
 	}
+	if rssFeed.Channel.Image.URL == "" {
+		rssFeed.Channel.Image.URL = rssFeed.Channel.ITunesImage.Href
+	}
+	// use Dublin Core date for items without pubDate
+	for i := range rssFeed.Channel.Item {
+		if rssFeed.Channel.Item[i].PubDate == "" {
+			rssFeed.Channel.Item[i].PubDate = rssFeed.Channel.Item[i].DCDate
+		}
+	}
 	// fmt.Println(rssFeed)
-	return &rssFeed, nil
+	return &rssFeed, header, nil
 }
 
 func handlerbrowse(s *state, cmd command, user database.User) error {
@@ -365,15 +861,33 @@ func handlerbrowse(s *state, cmd command, user database.User) error {
 	)
 	if len(cmd.args) == 0 {
 		fmt.Printf("%s command allows optional limit to posts returned\n", cmd.name)
-	} else if len(cmd.args) == 1 {
-		limit, err = strconv.Atoi(cmd.args[0])
-		if err != nil {
-			return fmt.Errorf("%s command requires integer limit value\n", cmd.name)
+	}
+	for i := 0; i < len(cmd.args); i++ {
+		switch cmd.args[i] {
+		case "--author", "--category":
+			if i+1 == len(cmd.args) {
+				return fmt.Errorf("%s command option %s requires a name\n", cmd.name, cmd.args[i])
+			}
+			if cmd.args[i] == "--author" {
+				dbGetPostsParams.Author = nullString(cmd.args[i+1])
+			} else {
+				dbGetPostsParams.Category = nullString(cmd.args[i+1])
+			}
+			i++
+		default:
+			limit, err = strconv.Atoi(cmd.args[i])
+			if err != nil {
+				return fmt.Errorf("%s command requires integer limit value\n", cmd.name)
+			}
 		}
-	} else {
-		return fmt.Errorf("%s command only allows optional limit argument\n", cmd.name)
 	}
 	fmt.Printf("%s command will limit posts returned to %d\n", cmd.name, limit)
+	if dbGetPostsParams.Author.Valid {
+		fmt.Printf("%s command will only return posts by author '%s'\n", cmd.name, dbGetPostsParams.Author.String)
+	}
+	if dbGetPostsParams.Category.Valid {
+		fmt.Printf("%s command will only return posts in category '%s'\n", cmd.name, dbGetPostsParams.Category.String)
+	}
 
 	// Get most recent posts (up to limit) in database from all feeds followed by current user
 	dbGetPostsParams.Name = s.config.UserName
@@ -387,21 +901,41 @@ func handlerbrowse(s *state, cmd command, user database.User) error {
 		fmt.Printf("\tID = %v\n", dbPost.ID)
 		fmt.Printf("\tCreated At = %v\n", dbPost.CreatedAt)
 		fmt.Printf("\tUpdated At = %v\n", dbPost.UpdatedAt)
+		// flag posts edited by their feed after they were first seen
+		if dbPost.EditedAt.Valid {
+			revisions, err := s.db.GetPostRevisionCount(ctx, dbPost.ID)
+			if err != nil {
+				return fmt.Errorf("%s command database select query error: %v\n", cmd.name, err)
+			}
+			fmt.Printf("\tEdited At = %v (%d earlier revisions)\n", dbPost.EditedAt.Time, revisions)
+		}
 		if dbPost.Title.Valid {
 			fmt.Printf("\tTitle = %s\n", dbPost.Title.String)
 		}
 		fmt.Printf("\tUrl = %s\n", dbPost.Url)
-		if dbPost.Description.Valid {
-			fmt.Printf("\tDescription = %s\n", dbPost.Description.String)
+		// show full article body when available, otherwise the description
+		if dbPost.Content.Valid {
+			fmt.Printf("\tContent =\n%s\n", renderPostBody(dbPost.Content.String))
+		} else if dbPost.Description.Valid {
+			fmt.Printf("\tDescription =\n%s\n", renderPostBody(dbPost.Description.String))
 		}
 		fmt.Printf("\tPublication Date = %s\n", dbPost.PublishedAt)
 		fmt.Printf("\tFeed ID = %s\n", dbPost.FeedID)
+		err = printAuthorsAndCategories(ctx, s, dbPost.ID)
+		if err != nil {
+			return fmt.Errorf("%s command database select query error: %v\n", cmd.name, err)
+		}
+		err = printEnclosures(ctx, s, dbPost.ID)
+		if err != nil {
+			return fmt.Errorf("%s command database select query error: %v\n", cmd.name, err)
+		}
 		fmt.Println()
 
-		_, err = getURL(ctx, dbPost.Url)
+		_, _, err = getURL(ctx, dbPost.Url)
 		// _post, err = getURL(ctx, dbPost.Url)
 		if err != nil {
-			return fmt.Errorf("Error getting post %s\n", dbPost.Url)
+			// post may have been removed since it was published
+			fmt.Printf("Error getting post %s: %v\n", dbPost.Url, err)
 		}
 		// fmt.Println(len(string(post)))
 	}
@@ -416,12 +950,24 @@ func handleraddfeed(s *state, cmd command, user database.User) error {
 		dbFeed       database.Feed
 		dbFFParams   database.CreateFeedFollowParams
 		dbFeedFollow database.CreateFeedFollowRow
+		feedURL      string
+		fetch        bool
+		added        int
 		err          error
 	)
 	if len(cmd.args) < 2 {
 		fmt.Printf("%s command requires feed name and URL\n", cmd.name)
 		return fmt.Errorf("%s command requires feed name and URL\n", cmd.name)
 	}
+	for _, arg := range cmd.args[2:] {
+		switch arg {
+		case "--fetch":
+			fetch = true
+		default:
+			fmt.Printf("%s command option '%s' not recognised\n", cmd.name, arg)
+			return fmt.Errorf("%s command option '%s' not recognised\n", cmd.name, arg)
+		}
+	}
 	// Check for existing feed in database
 	dbFeed, err = s.db.GetFeed(ctx, cmd.args[0])
 	if err == nil && dbFeed.Name == cmd.args[0] {
@@ -432,12 +978,19 @@ func handleraddfeed(s *state, cmd command, user database.User) error {
 	// 	return fmt.Errorf("addfeed command database select query error: %v\n", err)
 	// }
 
+	// Discover feed URL when URL of web page is provided
+	feedURL, err = discoverFeed(ctx, cmd.args[1])
+	if err != nil {
+		fmt.Printf("Unable to find feed at '%s'\n", cmd.args[1])
+		return fmt.Errorf("%s command feed discovery error: %v\n", cmd.name, err)
+	}
+
 	// Create new feed in database
 	dbParams.ID = uuid.New()
 	dbParams.CreatedAt = time.Now()
 	dbParams.UpdatedAt = dbParams.CreatedAt
 	dbParams.Name = cmd.args[0]
-	dbParams.Url = cmd.args[1]
+	dbParams.Url = feedURL
 	dbParams.UserID = user.ID
 	dbFeed, err = s.db.CreateFeed(ctx, dbParams)
 	if err != nil {
@@ -471,6 +1024,17 @@ func handleraddfeed(s *state, cmd command, user database.User) error {
 	fmt.Printf("\tUserID = %v\n", dbFeedFollow.UserID)
 	fmt.Printf("\tFeedID = %v\n", dbFeedFollow.FeedID)
 	fmt.Printf("feed '%s' followed by '%s'\n", dbFeedFollow.FeedName, dbFeedFollow.UserName)
+
+	// Fetch posts of new feed without waiting for agg
+	if fetch {
+		fmt.Printf("Fetching feed '%s'...\n", dbFeed.Name)
+		added, err = scrapeFeedNow(ctx, s, dbFeed)
+		if err != nil {
+			fmt.Printf("Error fetching feed '%s': %v", dbFeed.Name, err)
+			return fmt.Errorf("%s command fetch error: %v", cmd.name, err)
+		}
+		fmt.Printf("%d posts added from feed '%s'\n", added, dbFeed.Name)
+	}
 	return nil
 }
 
@@ -493,6 +1057,40 @@ func handlerfeeds(s *state, cmd command) error {
 	for _, feed := range dbFeeds {
 		fmt.Printf("* %s\n", feed.Name)
 		fmt.Printf("* %s\n", feed.Url)
+		// Channel metadata from most recent fetch of feed
+		if feed.SiteTitle.Valid {
+			fmt.Printf("  Title: %s\n", feed.SiteTitle.String)
+		}
+		if feed.SiteLink.Valid {
+			fmt.Printf("  Site: %s\n", feed.SiteLink.String)
+		}
+		if feed.Description.Valid {
+			fmt.Printf("  Description: %s\n", feed.Description.String)
+		}
+		if feed.Language.Valid {
+			fmt.Printf("  Language: %s\n", feed.Language.String)
+		}
+		if feed.ImageUrl.Valid {
+			fmt.Printf("  Image: %s\n", feed.ImageUrl.String)
+		}
+		if feed.Generator.Valid {
+			fmt.Printf("  Generator: %s\n", feed.Generator.String)
+		}
+		// Fetch status
+		if feed.LastSuccessAt.Valid {
+			fmt.Printf("  Last success: %s\n", feed.LastSuccessAt.Time.Format(time.DateTime))
+		}
+		if feed.ConsecutiveFailures > 0 {
+			fmt.Printf("  Failures: %d (last error: %s)\n", feed.ConsecutiveFailures, feed.LastError.String)
+		}
+		if feed.DisabledAt.Valid {
+			fmt.Printf("  Disabled: %s\n", feed.DisabledAt.Time.Format(time.DateTime))
+		} else if feed.NextFetchAt.Valid {
+			fmt.Printf("  Next fetch: %s\n", feed.NextFetchAt.Time.Format(time.DateTime))
+		}
+		if feed.FetchInterval.Valid {
+			fmt.Printf("  Fetch interval: %s\n", time.Duration(feed.FetchInterval.Int32)*time.Minute)
+		}
 
 		// Get current user from database
 		dbUser, err = s.db.GetUserById(ctx, feed.UserID)
@@ -511,6 +1109,7 @@ func handlerfollow(s *state, cmd command, user database.User) error {
 		dbParams     database.CreateFeedFollowParams
 		dbFeedFollow database.CreateFeedFollowRow
 		dbFeed       database.Feed
+		feedURL      string
 		err          error
 	)
 	if len(cmd.args) < 1 {
@@ -519,6 +1118,18 @@ func handlerfollow(s *state, cmd command, user database.User) error {
 	}
 	// Get feed by URL
 	dbFeed, err = s.db.GetFeedByURL(ctx, cmd.args[0])
+	if errors.Is(err, sql.ErrNoRows) {
+		// Discover feed URL when URL of web page is provided
+		feedURL, err = discoverFeed(ctx, cmd.args[0])
+		if err != nil {
+			fmt.Printf("Unable to find feed at '%s'\n", cmd.args[0])
+			return fmt.Errorf("%s command feed discovery error: %v\n", cmd.name, err)
+		}
+		dbFeed, err = s.db.GetFeedByURL(ctx, feedURL)
+		if errors.Is(err, sql.ErrNoRows) {
+			fmt.Printf("feed '%s' has not been added, use addfeed to add it\n", feedURL)
+		}
+	}
 	if err != nil {
 		return fmt.Errorf("%s command database select query error: %v\n", cmd.name, err)
 	}
@@ -587,6 +1198,143 @@ func handlerunfollow(s *state, cmd command, user database.User) error {
 	return nil
 }
 
+// Get feed from database by name, or by URL if no feed has that name
+func getFeedByNameOrURL(ctx context.Context, s *state, feed string) (database.Feed, error) {
+	dbFeed, err := s.db.GetFeed(ctx, feed)
+	if errors.Is(err, sql.ErrNoRows) {
+		dbFeed, err = s.db.GetFeedByURL(ctx, feed)
+	}
+	return dbFeed, err
+}
+
+func handlerdownload(s *state, cmd command, user database.User) error {
+	var (
+		ctx    context.Context = context.Background()
+		dbFeed database.Feed
+		err    error
+	)
+	// Download enclosures of all followed feeds if no feeds are given
+	if len(cmd.args) == 0 {
+		fmt.Printf("Downloading enclosures of feeds followed by '%s' to %s\n", user.Name, s.config.DownloadPath())
+		err = downloadFollowedFeeds(ctx, s, user.Name)
+		if err != nil {
+			return fmt.Errorf("%s command error: %v\n", cmd.name, err)
+		}
+		return nil
+	}
+	for _, arg := range cmd.args {
+		dbFeed, err = getFeedByNameOrURL(ctx, s, arg)
+		if err != nil {
+			fmt.Printf("feed '%s' does not exist in database\n", arg)
+			return fmt.Errorf("%s command database select query error: %v\n", cmd.name, err)
+		}
+		fmt.Printf("  Downloading enclosures of feed %s to %s...\n", dbFeed.Name, s.config.DownloadPath())
+		err = downloadFeed(ctx, s, dbFeed)
+		if err != nil {
+			return fmt.Errorf("%s command error: %v\n", cmd.name, err)
+		}
+	}
+	return nil
+}
+
+func handlerdownloadkeep(s *state, cmd command) error {
+	var (
+		ctx      context.Context = context.Background()
+		dbFeed   database.Feed
+		dbParams database.SetFeedDownloadKeepParams
+		keep     int
+		err      error
+	)
+	if len(cmd.args) != 2 {
+		fmt.Printf("%s command requires feed and number of posts to keep downloads of\n", cmd.name)
+		return fmt.Errorf("%s command requires feed and number of posts to keep downloads of\n", cmd.name)
+	}
+	keep, err = strconv.Atoi(cmd.args[1])
+	if err != nil || keep < 0 {
+		return fmt.Errorf("%s command requires non-negative integer number of downloads\n", cmd.name)
+	}
+	dbFeed, err = getFeedByNameOrURL(ctx, s, cmd.args[0])
+	if err != nil {
+		fmt.Printf("feed '%s' does not exist in database\n", cmd.args[0])
+		return fmt.Errorf("%s command database select query error: %v\n", cmd.name, err)
+	}
+	dbParams.ID = dbFeed.ID
+	dbParams.UpdatedAt = time.Now()
+	dbParams.DownloadKeep = sql.NullInt32{Int32: int32(keep), Valid: true}
+	err = s.db.SetFeedDownloadKeep(ctx, dbParams)
+	if err != nil {
+		return fmt.Errorf("%s command database update query error: %v\n", cmd.name, err)
+	}
+	if keep == 0 {
+		fmt.Printf("all downloads will be kept for feed '%s'\n", dbFeed.Name)
+	} else {
+		fmt.Printf("downloads of last %d posts will be kept for feed '%s'\n", keep, dbFeed.Name)
+	}
+	return nil
+}
+
+func handlerfetch(s *state, cmd command) error {
+	var (
+		ctx    context.Context = context.Background()
+		dbFeed database.Feed
+		added  int
+		failed int
+		err    error
+	)
+	if len(cmd.args) == 0 {
+		fmt.Printf("%s command requires feed name or url\n", cmd.name)
+		return fmt.Errorf("%s command requires feed name or url\n", cmd.name)
+	}
+	for _, feed := range cmd.args {
+		dbFeed, err = getFeedByNameOrURL(ctx, s, feed)
+		if err != nil {
+			fmt.Printf("feed '%s' does not exist in database\n", feed)
+			return fmt.Errorf("%s command database select query error: %v\n", cmd.name, err)
+		}
+		fmt.Printf("Fetching feed '%s'...\n", dbFeed.Name)
+		added, err = scrapeFeedNow(ctx, s, dbFeed)
+		if err != nil {
+			// carry on with other feeds
+			fmt.Printf("Error fetching feed '%s': %v", dbFeed.Name, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%d posts added from feed '%s'\n", added, dbFeed.Name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d feeds failed to fetch\n", failed)
+	}
+	return nil
+}
+
+func handlerenablefeed(s *state, cmd command) error {
+	var (
+		ctx      context.Context = context.Background()
+		dbFeed   database.Feed
+		dbParams database.EnableFeedParams
+		err      error
+	)
+	if len(cmd.args) == 0 {
+		fmt.Printf("%s command requires feed name or url\n", cmd.name)
+		return fmt.Errorf("%s command requires feed name or url\n", cmd.name)
+	}
+	for _, feed := range cmd.args {
+		dbFeed, err = getFeedByNameOrURL(ctx, s, feed)
+		if err != nil {
+			fmt.Printf("feed '%s' does not exist in database\n", feed)
+			return fmt.Errorf("%s command database select query error: %v\n", cmd.name, err)
+		}
+		dbParams.ID = dbFeed.ID
+		dbParams.UpdatedAt = time.Now()
+		err = s.db.EnableFeed(ctx, dbParams)
+		if err != nil {
+			return fmt.Errorf("%s command database update query error: %v\n", cmd.name, err)
+		}
+		fmt.Printf("feed '%s' enabled and failures cleared\n", dbFeed.Name)
+	}
+	return nil
+}
+
 func middlewareLoggedIn(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
 	return func(s *state, cmd command) error {
 		// Get current user from database
@@ -608,6 +1356,7 @@ func main() {
 	db, err := sql.Open("postgres", cfg.DbURL)
 	dbQueries := database.New(db)
 	as.db = dbQueries
+	as.sqlDB = db
 
 	// Create commands structure and initialize map of handler functions
 	ch := new(commands)
@@ -623,6 +1372,10 @@ func main() {
 	ch.register("unfollow", middlewareLoggedIn(handlerunfollow))
 	ch.register("agg", handleragg)
 	ch.register("browse", middlewareLoggedIn(handlerbrowse))
+	ch.register("download", middlewareLoggedIn(handlerdownload))
+	ch.register("downloadkeep", handlerdownloadkeep)
+	ch.register("enablefeed", handlerenablefeed)
+	ch.register("fetch", handlerfetch)
 
 	if len(os.Args) < 2 {
 		fmt.Println("Insufficient arguments provided")
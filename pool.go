@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dragonicorn/gator/internal/database"
+)
+
+// Options controlling how agg scrapes feeds
+type scrapeOptions struct {
+	workers     int
+	hostWorkers int
+	timeout     time.Duration
+}
+
+// Totals for a round of scraping due feeds
+type scrapeSummary struct {
+	fetched int
+	failed  int
+	added   int
+}
+
+// Feeds claimed from the database waiting to be scraped by a pool of
+// workers, limiting the number scraped at the same time from each host
+type scrapePool struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	ctx       context.Context
+	s         *state
+	opts      scrapeOptions
+	pending   []database.Feed
+	active    map[string]int
+	exhausted bool
+	summary   scrapeSummary
+}
+
+// Return host of feed URL, used to limit concurrent requests to each host
+func feedHost(feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil || u.Hostname() == "" {
+		return feedURL
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// Claim more feeds which are due to be fetched, leasing them so that other
+// agg processes sharing the database skip them until the lease expires
+// (should this process die before recording the result of the fetch)
+func (p *scrapePool) claim() {
+	var (
+		dbFeeds  []database.Feed
+		dbParams database.ClaimFeedsToFetchParams
+		err      error
+	)
+	dbParams.Now = time.Now()
+	// long enough for a feed to wait for busy hosts and then be fetched
+	dbParams.ClaimedUntil = dbParams.Now.Add(p.opts.timeout * time.Duration(p.opts.workers+1))
+	dbParams.MaxFeeds = int32(p.opts.workers - len(p.pending))
+	dbFeeds, err = p.s.db.ClaimFeedsToFetch(p.ctx, dbParams)
+	if err != nil {
+		fmt.Printf("Unable to claim feeds to fetch from database: %v\n", err)
+	}
+	if len(dbFeeds) == 0 {
+		p.exhausted = true
+	}
+	p.pending = append(p.pending, dbFeeds...)
+	p.summary.fetched += len(dbFeeds)
+}
+
+// Take next feed whose host is below its limit, claiming more feeds when
+// few are pending, or waiting for a feed to finish if every pending feed is
+// on a busy host. Reports false when no feeds are left to fetch, or on
+// shutdown, when claims on pending feeds are released.
+func (p *scrapePool) next() (database.Feed, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for {
+		if p.ctx.Err() != nil {
+			for _, dbFeed := range p.pending {
+				err := releaseFeedClaim(context.WithoutCancel(p.ctx), p.s, dbFeed)
+				if err != nil {
+					fmt.Printf("%v", err)
+				}
+			}
+			p.summary.fetched -= len(p.pending)
+			p.pending = nil
+			return database.Feed{}, false
+		}
+		for i, dbFeed := range p.pending {
+			host := feedHost(dbFeed.Url)
+			if p.active[host] < p.opts.hostWorkers {
+				p.pending = slices.Delete(p.pending, i, i+1)
+				p.active[host]++
+				return dbFeed, true
+			}
+		}
+		if !p.exhausted && len(p.pending) < p.opts.workers {
+			p.claim()
+			continue
+		}
+		if len(p.pending) == 0 {
+			return database.Feed{}, false
+		}
+		p.cond.Wait()
+	}
+}
+
+// Release host of finished feed, counting posts added or failure
+func (p *scrapePool) done(dbFeed database.Feed, added int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active[feedHost(dbFeed.Url)]--
+	p.summary.added += added
+	if err != nil {
+		p.summary.failed++
+	}
+	p.cond.Broadcast()
+}
+
+// Claim and scrape due feeds with a pool of workers, each feed with its own
+// timeout, returning totals once all feeds are done. Cancelling ctx stops
+// claiming feeds and aborts fetches in progress.
+func scrapeConcurrently(ctx context.Context, s *state, opts scrapeOptions) scrapeSummary {
+	var (
+		pool scrapePool = scrapePool{ctx: ctx, s: s, opts: opts, active: make(map[string]int)}
+		wg   sync.WaitGroup
+	)
+	pool.cond = sync.NewCond(&pool.mu)
+	for range opts.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				dbFeed, ok := pool.next()
+				if !ok {
+					return
+				}
+				feedCtx, cancel := context.WithTimeout(ctx, opts.timeout)
+				added, err := scrapeFeed(feedCtx, s, dbFeed)
+				cancel()
+				if err != nil {
+					// carry on with other feeds
+					fmt.Printf("  Error scraping feed %s: %v", dbFeed.Name, err)
+				}
+				pool.done(dbFeed, added, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return pool.summary
+}
+
+// Release claim on feed so that it can be fetched by another process
+func releaseFeedClaim(ctx context.Context, s *state, dbFeed database.Feed) error {
+	var (
+		dbParams database.ReleaseFeedClaimParams
+		err      error
+	)
+	dbParams.ID = dbFeed.ID
+	dbParams.UpdatedAt = time.Now()
+	err = s.db.ReleaseFeedClaim(ctx, dbParams)
+	if err != nil {
+		return fmt.Errorf("release feed claim database update query error: %v\n", err)
+	}
+	return nil
+}
+
+// Claim and scrape feed immediately, unless another process is fetching it,
+// returning number of posts added
+func scrapeFeedNow(ctx context.Context, s *state, dbFeed database.Feed) (int, error) {
+	var (
+		dbClaimed database.Feed
+		dbParams  database.ClaimFeedParams
+		timeout   time.Duration = s.config.ScrapeTimeout()
+		err       error
+	)
+	dbParams.Now = time.Now()
+	dbParams.ClaimedUntil = dbParams.Now.Add(timeout)
+	dbParams.ID = dbFeed.ID
+	dbClaimed, err = s.db.ClaimFeed(ctx, dbParams)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("feed '%s' is being fetched by another process\n", dbFeed.Name)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("claim feed database update query error: %v\n", err)
+	}
+	feedCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return scrapeFeed(feedCtx, s, dbClaimed)
+}
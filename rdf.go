@@ -0,0 +1,43 @@
+package main
+
+import (
+	"html"
+	"strings"
+)
+
+// RSS 1.0 feed, where items are siblings of the channel under rdf:RDF
+type RDFFeed struct {
+	Channel struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		Language    string `xml:"http://purl.org/dc/elements/1.1/ language"`
+		// syndication module hints on how often feed should be fetched
+		UpdatePeriod    string `xml:"http://purl.org/rss/1.0/modules/syndication/ updatePeriod"`
+		UpdateFrequency string `xml:"http://purl.org/rss/1.0/modules/syndication/ updateFrequency"`
+	} `xml:"channel"`
+	Image struct {
+		URL string `xml:"url"`
+	} `xml:"image"`
+	Item []RSSItem `xml:"item"`
+}
+
+// Convert RDF feed into RSSFeed so items share the RSS post pipeline
+func (f *RDFFeed) rssFeed() *RSSFeed {
+	var rssFeed RSSFeed
+	rssFeed.Channel.Title = html.UnescapeString(f.Channel.Title)
+	rssFeed.Channel.Link = strings.TrimSpace(f.Channel.Link)
+	rssFeed.Channel.Description = html.UnescapeString(f.Channel.Description)
+	rssFeed.Channel.Language = f.Channel.Language
+	rssFeed.Channel.Image.URL = strings.TrimSpace(f.Image.URL)
+	rssFeed.Channel.UpdatePeriod = f.Channel.UpdatePeriod
+	rssFeed.Channel.UpdateFrequency = f.Channel.UpdateFrequency
+	for _, rssItem := range f.Item {
+		rssItem.Link = strings.TrimSpace(rssItem.Link)
+		if rssItem.PubDate == "" {
+			rssItem.PubDate = rssItem.DCDate
+		}
+		rssFeed.Channel.Item = append(rssFeed.Channel.Item, rssItem)
+	}
+	return &rssFeed
+}
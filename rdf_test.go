@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestRDFFeedRSSFeed(t *testing.T) {
+	const feed = `<?xml version="1.0" encoding="utf-8"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:sy="http://purl.org/rss/1.0/modules/syndication/">
+<channel rdf:about="https://example.com/">
+<title>Example</title>
+<link> https://example.com/ </link>
+<description>About</description>
+<dc:language>en</dc:language>
+<sy:updatePeriod>daily</sy:updatePeriod>
+<sy:updateFrequency>2</sy:updateFrequency>
+<items><rdf:Seq><rdf:li rdf:resource="https://example.com/1"/></rdf:Seq></items>
+</channel>
+<image rdf:about="https://example.com/logo.png"><url>https://example.com/logo.png</url></image>
+<item rdf:about="https://example.com/1">
+<title>First</title>
+<link>
+  https://example.com/1
+</link>
+<description>one</description>
+<dc:date>2024-03-01T10:00:00Z</dc:date>
+<dc:creator>Ann</dc:creator>
+</item>
+<item rdf:about="https://example.com/2">
+<title>Second</title>
+<link>https://example.com/2</link>
+<dc:date>2024-03-02T10:00:00+01:00</dc:date>
+</item>
+</rdf:RDF>`
+	var rdfFeed RDFFeed
+	err := xml.Unmarshal([]byte(feed), &rdfFeed)
+	if err != nil {
+		t.Fatalf("xml.Unmarshal error: %v", err)
+	}
+	rssFeed := rdfFeed.rssFeed()
+	if len(rssFeed.Channel.Item) != 2 {
+		t.Fatalf("got %d items, want 2", len(rssFeed.Channel.Item))
+	}
+	tests := []struct {
+		field string
+		got   string
+		want  string
+	}{
+		{"channel title", rssFeed.Channel.Title, "Example"},
+		{"channel link", rssFeed.Channel.Link, "https://example.com/"},
+		{"channel language", rssFeed.Channel.Language, "en"},
+		{"channel image", rssFeed.Channel.Image.URL, "https://example.com/logo.png"},
+		{"update period", rssFeed.Channel.UpdatePeriod, "daily"},
+		{"update frequency", rssFeed.Channel.UpdateFrequency, "2"},
+		{"item title", rssFeed.Channel.Item[0].Title, "First"},
+		{"item link", rssFeed.Channel.Item[0].Link, "https://example.com/1"},
+		{"item description", rssFeed.Channel.Item[0].Description, "one"},
+		{"dc:date as pubDate", rssFeed.Channel.Item[0].PubDate, "2024-03-01T10:00:00Z"},
+		{"dc:creator", rssFeed.Channel.Item[0].DCCreator[0], "Ann"},
+		{"second item link", rssFeed.Channel.Item[1].Link, "https://example.com/2"},
+		{"second dc:date", rssFeed.Channel.Item[1].PubDate, "2024-03-02T10:00:00+01:00"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.field, tt.got, tt.want)
+		}
+	}
+}
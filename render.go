@@ -0,0 +1,323 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Marks start of preformatted line, which is not wrapped
+const preLine = "\x00"
+
+// Tags which start a new paragraph
+var htmlBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"dd": true, "div": true, "dl": true, "dt": true, "figcaption": true,
+	"figure": true, "footer": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"main": true, "nav": true, "ol": true, "p": true, "pre": true,
+	"section": true, "table": true, "ul": true,
+}
+
+// Converts post HTML into plain text with numbered link references
+type htmlRenderer struct {
+	text  strings.Builder
+	space bool
+	links []string
+	index map[string]int
+	href  []string
+	lists []int
+	pre   int
+	skip  int
+}
+
+// Return number of link reference for URL, adding it if not already present
+func (r *htmlRenderer) link(url string) int {
+	if n, ok := r.index[url]; ok {
+		return n
+	}
+	r.links = append(r.links, url)
+	r.index[url] = len(r.links)
+	return len(r.links)
+}
+
+// Return whether text so far ends with a line break (or is empty)
+func (r *htmlRenderer) atLineStart() bool {
+	s := r.text.String()
+	return s == "" || strings.HasSuffix(s, "\n") || strings.HasSuffix(s, preLine)
+}
+
+// End current line
+func (r *htmlRenderer) newline() {
+	if !r.atLineStart() {
+		r.text.WriteString("\n")
+	}
+	r.space = false
+}
+
+// End current paragraph, leaving a blank line before the next one
+func (r *htmlRenderer) block() {
+	r.newline()
+	s := r.text.String()
+	if s != "" && !strings.HasSuffix(s, "\n\n") {
+		r.text.WriteString("\n")
+	}
+}
+
+// Write text, collapsing white space unless preformatted
+func (r *htmlRenderer) write(s string) {
+	if r.skip > 0 || s == "" {
+		return
+	}
+	if r.pre > 0 {
+		for i, line := range strings.Split(s, "\n") {
+			if i > 0 {
+				r.text.WriteString("\n")
+			}
+			if r.atLineStart() {
+				r.text.WriteString(preLine)
+			}
+			r.text.WriteString(line)
+		}
+		return
+	}
+	if strings.TrimLeft(s, " \t\r\n") != s {
+		r.space = true
+	}
+	words := strings.Fields(s)
+	for i, word := range words {
+		if (i > 0 || r.space) && !r.atLineStart() && !strings.HasSuffix(r.text.String(), " ") {
+			r.text.WriteString(" ")
+		}
+		r.text.WriteString(word)
+		r.space = false
+	}
+	if len(words) > 0 && strings.TrimRight(s, " \t\r\n") != s {
+		r.space = true
+	}
+}
+
+// Handle start or end tag
+func (r *htmlRenderer) tag(name string, attrs map[string]string, end bool) {
+	switch {
+	case name == "script" || name == "style" || name == "head":
+		if end && r.skip > 0 {
+			r.skip--
+		} else if !end {
+			r.skip++
+		}
+	case name == "br":
+		if r.pre > 0 {
+			r.write("\n")
+		} else {
+			r.newline()
+		}
+	case name == "a" && !end:
+		r.href = append(r.href, attrs["href"])
+	case name == "a" && end && len(r.href) > 0:
+		href := r.href[len(r.href)-1]
+		r.href = r.href[:len(r.href)-1]
+		if href != "" && !strings.HasPrefix(href, "#") {
+			r.write(fmt.Sprintf("[%d]", r.link(href)))
+		}
+	case name == "img" && !end:
+		alt := strings.TrimSpace(attrs["alt"])
+		if alt == "" {
+			alt = "image"
+		} else {
+			alt = "image: " + alt
+		}
+		if attrs["src"] != "" {
+			r.write(fmt.Sprintf(" [%s][%d] ", alt, r.link(attrs["src"])))
+		} else {
+			r.write(fmt.Sprintf(" [%s] ", alt))
+		}
+	case name == "ul" || name == "ol":
+		if end && len(r.lists) > 0 {
+			r.lists = r.lists[:len(r.lists)-1]
+		} else if !end {
+			// 0 for unordered lists, otherwise number of next item
+			if name == "ol" {
+				r.lists = append(r.lists, 1)
+			} else {
+				r.lists = append(r.lists, 0)
+			}
+		}
+		r.block()
+	case name == "li" && !end:
+		r.newline()
+		r.text.WriteString(strings.Repeat("  ", max(len(r.lists)-1, 0)))
+		if len(r.lists) > 0 && r.lists[len(r.lists)-1] > 0 {
+			r.text.WriteString(strconv.Itoa(r.lists[len(r.lists)-1]) + ". ")
+			r.lists[len(r.lists)-1]++
+		} else {
+			r.text.WriteString("* ")
+		}
+	case name == "tr" || name == "li":
+		r.newline()
+	case name == "td" || name == "th":
+		if !end {
+			r.write(" ")
+		}
+	case name == "hr" && !end:
+		r.block()
+		r.text.WriteString("----\n")
+	case htmlBlockTags[name]:
+		if name == "pre" {
+			if end && r.pre > 0 {
+				r.pre--
+			} else if !end {
+				r.pre++
+			}
+		}
+		r.block()
+	}
+}
+
+// Parse HTML, calling tag and write for tags and text
+func (r *htmlRenderer) parse(s string) {
+	for s != "" {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			r.write(html.UnescapeString(s))
+			return
+		}
+		r.write(html.UnescapeString(s[:i]))
+		s = s[i:]
+		switch {
+		case strings.HasPrefix(s, "<!--"):
+			i = strings.Index(s, "-->")
+			if i < 0 {
+				return
+			}
+			s = s[i+3:]
+			continue
+		case strings.HasPrefix(s, "<![CDATA["):
+			i = strings.Index(s, "]]>")
+			if i < 0 {
+				r.write(s[9:])
+				return
+			}
+			r.write(s[9:i])
+			s = s[i+3:]
+			continue
+		}
+		// tag ends at first '>' outside quoted attribute values
+		quote := byte(0)
+		end := -1
+		for j := 1; j < len(s) && end < 0; j++ {
+			switch {
+			case quote != 0:
+				if s[j] == quote {
+					quote = 0
+				}
+			case s[j] == '"' || s[j] == '\'':
+				quote = s[j]
+			case s[j] == '>':
+				end = j
+			}
+		}
+		if end < 0 {
+			r.write(html.UnescapeString(s))
+			return
+		}
+		tag := s[1:end]
+		s = s[end+1:]
+		closing := strings.HasPrefix(tag, "/")
+		tag = strings.TrimPrefix(tag, "/")
+		raw := tag
+		if i = strings.IndexAny(tag, " \t\r\n/"); i >= 0 {
+			raw = tag[:i]
+		}
+		// lower case only for matching, as it may change length of name
+		name := strings.ToLower(raw)
+		if name == "" || !(name[0] >= 'a' && name[0] <= 'z') {
+			// not a tag (e.g. "<" in text or <!DOCTYPE>)
+			if name == "" {
+				r.write("<" + tag + ">")
+			}
+			continue
+		}
+		r.tag(name, htmlAttrs(tag[len(raw):]), closing)
+	}
+}
+
+// Wrap line at width, indenting continuation lines to align with list items
+func wrapLine(line string, width int) []string {
+	var (
+		lines  []string
+		indent string
+		length int
+		words  []string
+	)
+	trimmed := strings.TrimLeft(line, " ")
+	indent = line[:len(line)-len(trimmed)]
+	if marker, _, ok := strings.Cut(trimmed, " "); ok && (marker == "*" || strings.HasSuffix(marker, ".") && strings.Trim(marker, "0123456789.") == "") {
+		indent += strings.Repeat(" ", len(marker)+1)
+	}
+	words = strings.Fields(trimmed)
+	line = line[:len(line)-len(trimmed)]
+	length = utf8.RuneCountInString(line)
+	for i, word := range words {
+		n := utf8.RuneCountInString(word)
+		if i > 0 && length+1+n > width {
+			lines = append(lines, line)
+			line = indent + word
+			length = utf8.RuneCountInString(line)
+			continue
+		}
+		if i > 0 {
+			line += " "
+			length++
+		}
+		line += word
+		length += n
+	}
+	return append(lines, line)
+}
+
+// Return width of terminal from COLUMNS environment variable, or 80
+func terminalWidth() int {
+	width, err := strconv.Atoi(os.Getenv("COLUMNS"))
+	if err != nil || width < 20 {
+		return 80
+	}
+	return width
+}
+
+// Render post HTML as plain text wrapped at width, with numbered references
+// for links and images listed after the text
+func renderHTML(s string, width int) string {
+	var (
+		r     htmlRenderer = htmlRenderer{index: make(map[string]int)}
+		lines []string
+	)
+	r.parse(s)
+	for _, line := range strings.Split(strings.TrimSpace(r.text.String()), "\n") {
+		if strings.HasPrefix(line, preLine) {
+			lines = append(lines, strings.TrimPrefix(line, preLine))
+			continue
+		}
+		lines = append(lines, wrapLine(line, width)...)
+	}
+	if len(r.links) > 0 {
+		lines = append(lines, "")
+		for i, link := range r.links {
+			lines = append(lines, fmt.Sprintf("[%d] %s", i+1, link))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Return text with prefix added to each line
+func indentText(text string, prefix string) string {
+	return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
+
+// Render post body for printing in a record, indented by two tabs
+func renderPostBody(s string) string {
+	return indentText(renderHTML(s, max(terminalWidth()-16, 20)), "\t\t")
+}
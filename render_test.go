@@ -0,0 +1,61 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRenderHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", ""},
+		{"paragraphs", "<p>one</p><p>two</p>", "one\n\ntwo"},
+		{"entities", "<p>a &amp; b</p>", "a & b"},
+		{"link reference", "<a href='u'>link</a> text", "link[1] text\n\n[1] u"},
+		{"list items", "<ul><li>one<li>two</ul>", "* one\n* two"},
+		{"cdata", "<p>a<![CDATA[x<y]]>b</p>", "ax<yb"},
+		{"comment", "a<!--c-->b", "ab"},
+		{"unterminated comment", "a<!-- unterminated", "a"},
+		{"unterminated cdata", "<p>hi</p><![CDATA[abcdef", "hi\n\nabcdef"},
+		{"unterminated short cdata", "<p>hi</p><![CDATA[ab", "hi\n\nab"},
+		{"empty unterminated cdata", "<![CDATA[", ""},
+		{"unterminated tag", "<p>x <a href=\"y>", "x <a href=\"y>"},
+		{"bare less than", "a < b", "a < b"},
+		{"trailing less than", "a <", "a <"},
+		{"lone less than", "<", "<"},
+		{"tag name changing length when lower cased", "<p>x <b\u023a> y</p>", "x y"},
+		{"tag name with invalid utf-8", "<A\xb8 href='u'>z</A>", "z"},
+		{"upper case tag with attributes", "<A HREF='u'>z</A>", "z[1]\n\n[1] u"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderHTML(tt.in, 80)
+			if got != tt.want {
+				t.Errorf("renderHTML(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapLine(t *testing.T) {
+	tests := []struct {
+		line  string
+		width int
+		want  []string
+	}{
+		{"short line", 20, []string{"short line"}},
+		{"one two three four", 9, []string{"one two", "three", "four"}},
+		{"* item that wraps", 10, []string{"* item", "  that", "  wraps"}},
+		{"12. item that wraps", 11, []string{"12. item", "    that", "    wraps"}},
+		{"unbreakablewordlongerthanwidth", 10, []string{"unbreakablewordlongerthanwidth"}},
+	}
+	for _, tt := range tests {
+		got := wrapLine(tt.line, tt.width)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("wrapLine(%q, %d) = %q, want %q", tt.line, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/dragonicorn/gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// Return hash of content of post as stored, to detect items edited since
+// they were first seen (migration 018 hashes existing posts the same way)
+func postContentHash(title, description, content string) string {
+	sum := sha256.Sum256([]byte(title + "\x1f" + description + "\x1f" + content))
+	return hex.EncodeToString(sum[:])
+}
+
+// Save current content of posts as revisions and replace it with the edited
+// content of their items, in one query for each
+func revisePosts(ctx context.Context, s *state, dbPostParams database.UpdatePostsContentParams) error {
+	var (
+		dbRevParams database.CreatePostRevisionsParams
+		err         error
+	)
+	if len(dbPostParams.Ids) == 0 {
+		return nil
+	}
+	dbRevParams.CreatedAt = dbPostParams.UpdatedAt
+	for _, postID := range dbPostParams.Ids {
+		dbRevParams.Ids = append(dbRevParams.Ids, uuid.New())
+		dbRevParams.PostIds = append(dbRevParams.PostIds, postID)
+	}
+	err = s.db.CreatePostRevisions(ctx, dbRevParams)
+	if err != nil {
+		return fmt.Errorf("post revision database insert query error: %v\n", err)
+	}
+	err = s.db.UpdatePostsContent(ctx, dbPostParams)
+	if err != nil {
+		return fmt.Errorf("post content database update query error: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"database/sql"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Longest interval between fetches requested by a feed which is honoured
+const feedMaxTTL = 24 * time.Hour
+
+// Interval between fetches of a feed, adapted to how often it publishes
+// posts within these limits, and used until it is known
+const (
+	feedMinInterval     = 15 * time.Minute
+	feedMaxInterval     = 24 * time.Hour
+	feedDefaultInterval = time.Hour
+)
+
+// Number of most recent posts used to estimate how often a feed publishes
+const feedIntervalPosts = 10
+
+// Length of syndication module (sy:updatePeriod) update periods
+var syUpdatePeriods = map[string]time.Duration{
+	"hourly":  time.Hour,
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+	"yearly":  365 * 24 * time.Hour,
+}
+
+// Return minimum number of minutes between fetches requested by feed, from
+// <ttl> or sy:updatePeriod and sy:updateFrequency, whichever is longer
+func feedTTL(rssFeed *RSSFeed) sql.NullInt32 {
+	var (
+		ttl       time.Duration
+		period    time.Duration
+		frequency int = 1
+	)
+	minutes, err := strconv.Atoi(strings.TrimSpace(rssFeed.Channel.TTL))
+	if err == nil && minutes > 0 {
+		ttl = time.Duration(minutes) * time.Minute
+	}
+	period = syUpdatePeriods[strings.ToLower(strings.TrimSpace(rssFeed.Channel.UpdatePeriod))]
+	if period > 0 {
+		n, err := strconv.Atoi(strings.TrimSpace(rssFeed.Channel.UpdateFrequency))
+		if err == nil && n > 0 {
+			frequency = n
+		}
+		ttl = max(ttl, period/time.Duration(frequency))
+	}
+	if ttl <= 0 {
+		return sql.NullInt32{}
+	}
+	ttl = min(ttl, feedMaxTTL)
+	return sql.NullInt32{Int32: int32(ttl / time.Minute), Valid: true}
+}
+
+// Return hours (GMT) in which feed asks not to be fetched, from <skipHours>
+func feedSkipHours(rssFeed *RSSFeed) []int32 {
+	var hours []int32
+	for _, hour := range rssFeed.Channel.SkipHours {
+		n, err := strconv.Atoi(strings.TrimSpace(hour))
+		if err != nil || n < 0 || n > 24 {
+			continue
+		}
+		// some feeds use 24 for midnight
+		hours = append(hours, int32(n%24))
+	}
+	return hours
+}
+
+// Return days in which feed asks not to be fetched, from <skipDays>
+func feedSkipDays(rssFeed *RSSFeed) []string {
+	var days []string
+	for _, day := range rssFeed.Channel.SkipDays {
+		for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+			if strings.EqualFold(strings.TrimSpace(day), weekday.String()) {
+				days = append(days, weekday.String())
+			}
+		}
+	}
+	return days
+}
+
+// Report whether feed asks not to be fetched in the hour (GMT) starting at t
+func skipped(t time.Time, skipHours []int32, skipDays []string) bool {
+	t = t.UTC()
+	for _, hour := range skipHours {
+		if int(hour) == t.Hour() {
+			return true
+		}
+	}
+	for _, day := range skipDays {
+		if day == t.Weekday().String() {
+			return true
+		}
+	}
+	return false
+}
+
+// Return interval between fetches adapted to publication times of posts in
+// feed, fetching about twice in the average time between its recent posts
+// (or since its last post, if longer), or the previous interval if unknown
+func adaptInterval(published []time.Time, now time.Time, previous sql.NullInt32) sql.NullInt32 {
+	var interval time.Duration
+	published = slices.DeleteFunc(slices.Clone(published), func(t time.Time) bool {
+		return t.After(now)
+	})
+	if len(published) < 2 {
+		return previous
+	}
+	slices.SortFunc(published, func(a, b time.Time) int {
+		return b.Compare(a)
+	})
+	published = published[:min(len(published), feedIntervalPosts)]
+	interval = published[0].Sub(published[len(published)-1]) / time.Duration(len(published)-1)
+	interval = max(interval, now.Sub(published[0])) / 2
+	interval = min(max(interval, feedMinInterval), feedMaxInterval)
+	return sql.NullInt32{Int32: int32(interval / time.Minute), Valid: true}
+}
+
+// Return time after which feed is next eligible to be fetched, after its
+// fetch interval (or TTL, if longer) and outside its skip hours and days
+func nextFetchTime(now time.Time, interval sql.NullInt32, ttl sql.NullInt32, skipHours []int32, skipDays []string) sql.NullTime {
+	var (
+		delay time.Duration = feedDefaultInterval
+		next  time.Time
+	)
+	if interval.Valid {
+		delay = time.Duration(interval.Int32) * time.Minute
+	}
+	if ttl.Valid {
+		delay = max(delay, time.Duration(ttl.Int32)*time.Minute)
+	}
+	next = now.Add(delay)
+	// move to start of next hour until outside skipped hours and days,
+	// giving up after a week in case every hour is skipped
+	for i := 0; i < 7*24 && skipped(next, skipHours, skipDays); i++ {
+		next = next.Truncate(time.Hour).Add(time.Hour)
+	}
+	return sql.NullTime{Time: next, Valid: true}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"database/sql"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFeedTTL(t *testing.T) {
+	tests := []struct {
+		ttl       string
+		period    string
+		frequency string
+		want      sql.NullInt32
+	}{
+		{"", "", "", sql.NullInt32{}},
+		{"60", "", "", sql.NullInt32{Int32: 60, Valid: true}},
+		{" 30 ", "", "", sql.NullInt32{Int32: 30, Valid: true}},
+		{"-5", "", "", sql.NullInt32{}},
+		{"abc", "", "", sql.NullInt32{}},
+		{"", "hourly", "", sql.NullInt32{Int32: 60, Valid: true}},
+		{"", "Daily", "4", sql.NullInt32{Int32: 360, Valid: true}},
+		{"90", "hourly", "2", sql.NullInt32{Int32: 90, Valid: true}},
+		{"", "weekly", "", sql.NullInt32{Int32: int32(feedMaxTTL / time.Minute), Valid: true}},
+		{"100000", "", "", sql.NullInt32{Int32: int32(feedMaxTTL / time.Minute), Valid: true}},
+	}
+	for _, tt := range tests {
+		var rssFeed RSSFeed
+		rssFeed.Channel.TTL = tt.ttl
+		rssFeed.Channel.UpdatePeriod = tt.period
+		rssFeed.Channel.UpdateFrequency = tt.frequency
+		got := feedTTL(&rssFeed)
+		if got != tt.want {
+			t.Errorf("feedTTL(ttl %q, period %q, frequency %q) = %v, want %v", tt.ttl, tt.period, tt.frequency, got, tt.want)
+		}
+	}
+}
+
+func TestFeedSkipHoursAndDays(t *testing.T) {
+	var rssFeed RSSFeed
+	rssFeed.Channel.SkipHours = []string{"0", " 7 ", "24", "25", "x"}
+	rssFeed.Channel.SkipDays = []string{"saturday", " Sunday ", "Someday"}
+	hours := feedSkipHours(&rssFeed)
+	if !slices.Equal(hours, []int32{0, 7, 0}) {
+		t.Errorf("feedSkipHours = %v, want [0 7 0]", hours)
+	}
+	days := feedSkipDays(&rssFeed)
+	if !slices.Equal(days, []string{"Saturday", "Sunday"}) {
+		t.Errorf("feedSkipDays = %v, want [Saturday Sunday]", days)
+	}
+}
+
+func TestNextFetchTime(t *testing.T) {
+	// Friday
+	now := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	hour := sql.NullInt32{Int32: 60, Valid: true}
+	tests := []struct {
+		name      string
+		interval  sql.NullInt32
+		ttl       sql.NullInt32
+		skipHours []int32
+		skipDays  []string
+		want      time.Time
+	}{
+		{"default", sql.NullInt32{}, sql.NullInt32{}, nil, nil, now.Add(feedDefaultInterval)},
+		{"interval", sql.NullInt32{Int32: 15, Valid: true}, sql.NullInt32{}, nil, nil, now.Add(15 * time.Minute)},
+		{"longer ttl", hour, sql.NullInt32{Int32: 120, Valid: true}, nil, nil, now.Add(2 * time.Hour)},
+		{"shorter ttl", hour, sql.NullInt32{Int32: 5, Valid: true}, nil, nil, now.Add(time.Hour)},
+		{"skip hours", hour, sql.NullInt32{}, []int32{11, 12}, nil, time.Date(2024, 3, 1, 13, 0, 0, 0, time.UTC)},
+		{"skip days", hour, sql.NullInt32{}, nil, []string{"Friday", "Saturday"}, time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got := nextFetchTime(now, tt.interval, tt.ttl, tt.skipHours, tt.skipDays)
+		if !got.Valid || !got.Time.Equal(tt.want) {
+			t.Errorf("%s: nextFetchTime = %v, want %v", tt.name, got.Time, tt.want)
+		}
+	}
+	// every hour skipped gives up after a week
+	all := []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+	got := nextFetchTime(now, hour, sql.NullInt32{}, nil, all)
+	if !got.Valid || got.Time.Sub(now) > 8*24*time.Hour {
+		t.Errorf("nextFetchTime with every day skipped = %v", got.Time)
+	}
+}
+
+func TestAdaptInterval(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	previous := sql.NullInt32{Int32: 60, Valid: true}
+	// posts every d, the latest d before now
+	every := func(d time.Duration, n int) []time.Time {
+		var published []time.Time
+		for i := 1; i <= n; i++ {
+			published = append(published, now.Add(-time.Duration(i)*d))
+		}
+		return published
+	}
+	tests := []struct {
+		name      string
+		published []time.Time
+		want      sql.NullInt32
+	}{
+		{"no posts", nil, previous},
+		{"one post", every(time.Hour, 1), previous},
+		{"future posts ignored", []time.Time{now.Add(time.Hour), now.Add(-time.Hour)}, previous},
+		{"every 4 hours", every(4*time.Hour, 5), sql.NullInt32{Int32: 120, Valid: true}},
+		{"busy feed", every(time.Minute, 20), sql.NullInt32{Int32: int32(feedMinInterval / time.Minute), Valid: true}},
+		{"quiet feed", every(10*24*time.Hour, 3), sql.NullInt32{Int32: int32(feedMaxInterval / time.Minute), Valid: true}},
+		// no new posts since, so interval grows with time since last post
+		{"stopped publishing", []time.Time{now.Add(-10 * time.Hour), now.Add(-11 * time.Hour)}, sql.NullInt32{Int32: 300, Valid: true}},
+	}
+	for _, tt := range tests {
+		got := adaptInterval(tt.published, now, previous)
+		if got != tt.want {
+			t.Errorf("%s: adaptInterval = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
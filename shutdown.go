@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Return context which is cancelled on the first SIGINT or SIGTERM, so that
+// work in progress can finish cleanly, exiting at once on a second signal
+func signalContext() (context.Context, context.CancelFunc) {
+	var signals chan os.Signal = make(chan os.Signal, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+		case <-ctx.Done():
+			return
+		}
+		fmt.Println("Shutting down once feeds being stored are finished (interrupt again to exit now)...")
+		cancel()
+		<-signals
+		fmt.Println("Exiting without waiting for feeds being stored")
+		os.Exit(1)
+	}()
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
@@ -0,0 +1,37 @@
+-- name: CreateAuthors :exec
+INSERT INTO authors (id, created_at, updated_at, name)
+SELECT
+    u.id,
+    sqlc.arg(created_at)::timestamp,
+    sqlc.arg(created_at)::timestamp,
+    u.name
+FROM unnest(
+    sqlc.arg(ids)::uuid[],
+    sqlc.arg(names)::text[]
+) AS u(id, name)
+ON CONFLICT (name) DO NOTHING;
+
+-- name: CreatePostAuthors :exec
+INSERT INTO postauthors (id, created_at, updated_at, post_id, author_id)
+SELECT
+    u.id,
+    sqlc.arg(created_at)::timestamp,
+    sqlc.arg(created_at)::timestamp,
+    u.post_id,
+    authors.id
+FROM unnest(
+    sqlc.arg(ids)::uuid[],
+    sqlc.arg(post_ids)::uuid[],
+    sqlc.arg(names)::text[]
+) AS u(id, post_id, name)
+    INNER JOIN authors ON authors.name = u.name
+ON CONFLICT DO NOTHING;
+
+-- name: GetAuthorsForPost :many
+SELECT authors.*
+FROM authors
+    INNER JOIN postauthors ON authors.id = postauthors.author_id
+WHERE
+    postauthors.post_id = $1
+ORDER BY
+    authors.name;
\ No newline at end of file
@@ -0,0 +1,37 @@
+-- name: CreateCategories :exec
+INSERT INTO categories (id, created_at, updated_at, name)
+SELECT
+    u.id,
+    sqlc.arg(created_at)::timestamp,
+    sqlc.arg(created_at)::timestamp,
+    u.name
+FROM unnest(
+    sqlc.arg(ids)::uuid[],
+    sqlc.arg(names)::text[]
+) AS u(id, name)
+ON CONFLICT (name) DO NOTHING;
+
+-- name: CreatePostCategories :exec
+INSERT INTO postcategories (id, created_at, updated_at, post_id, category_id)
+SELECT
+    u.id,
+    sqlc.arg(created_at)::timestamp,
+    sqlc.arg(created_at)::timestamp,
+    u.post_id,
+    categories.id
+FROM unnest(
+    sqlc.arg(ids)::uuid[],
+    sqlc.arg(post_ids)::uuid[],
+    sqlc.arg(names)::text[]
+) AS u(id, post_id, name)
+    INNER JOIN categories ON categories.name = u.name
+ON CONFLICT DO NOTHING;
+
+-- name: GetCategoriesForPost :many
+SELECT categories.*
+FROM categories
+    INNER JOIN postcategories ON categories.id = postcategories.category_id
+WHERE
+    postcategories.post_id = $1
+ORDER BY
+    categories.name;
\ No newline at end of file
@@ -0,0 +1,33 @@
+-- name: UpsertDownload :exec
+INSERT INTO downloads (id, created_at, updated_at, enclosure_id, feed_id, path, size, completed_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+ON CONFLICT (enclosure_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at, feed_id = EXCLUDED.feed_id, path = EXCLUDED.path, size = EXCLUDED.size, completed_at = EXCLUDED.completed_at;
+
+-- name: GetDownloadForEnclosure :one
+SELECT * FROM downloads WHERE $1 = enclosure_id;
+
+-- name: DeleteDownload :exec
+DELETE FROM downloads WHERE $1 = id;
+
+-- name: GetEnclosuresForFeed :many
+SELECT
+    enclosures.*,
+    posts.title AS post_title,
+    posts.published_at AS post_published_at
+FROM enclosures
+    INNER JOIN postfeeds ON postfeeds.post_id = enclosures.post_id
+    INNER JOIN posts ON posts.id = enclosures.post_id
+WHERE
+    postfeeds.feed_id = $1
+ORDER BY
+    posts.published_at DESC, posts.id, enclosures.created_at;
\ No newline at end of file
@@ -0,0 +1,27 @@
+-- name: CreateEnclosures :exec
+INSERT INTO enclosures (id, created_at, updated_at, post_id, url, mime_type, length, duration, episode, season)
+SELECT
+    u.id,
+    sqlc.arg(created_at)::timestamp,
+    sqlc.arg(created_at)::timestamp,
+    u.post_id,
+    u.url,
+    NULLIF(u.mime_type, ''),
+    NULLIF(u.length, -1),
+    NULLIF(u.duration, -1),
+    NULLIF(u.episode, -1),
+    NULLIF(u.season, -1)
+FROM unnest(
+    sqlc.arg(ids)::uuid[],
+    sqlc.arg(post_ids)::uuid[],
+    sqlc.arg(urls)::text[],
+    sqlc.arg(mime_types)::text[],
+    sqlc.arg(lengths)::bigint[],
+    sqlc.arg(durations)::int[],
+    sqlc.arg(episodes)::int[],
+    sqlc.arg(seasons)::int[]
+) AS u(id, post_id, url, mime_type, length, duration, episode, season)
+ON CONFLICT (post_id, url) DO NOTHING;
+
+-- name: GetEnclosuresForPost :many
+SELECT * FROM enclosures WHERE $1 = post_id ORDER BY created_at;
\ No newline at end of file
@@ -13,6 +13,9 @@ RETURNING *;
 -- name: GetFeed :one
 SELECT * FROM feeds WHERE $1 = name;
 
+-- name: GetFeedById :one
+SELECT * FROM feeds WHERE $1 = id;
+
 -- name: GetFeedByURL :one
 SELECT * FROM feeds WHERE $1 = url;
 
@@ -22,10 +25,57 @@ SELECT * FROM feeds;
 -- name: DeleteFeeds :exec
 DELETE FROM feeds;
 
--- name: MarkFeedFetched :exec
+-- name: ClaimFeed :one
+UPDATE feeds
+SET updated_at = sqlc.arg(now)::timestamp, claimed_until = sqlc.arg(claimed_until)::timestamp
+WHERE id = sqlc.arg(id) AND (claimed_until IS NULL OR claimed_until <= sqlc.arg(now)::timestamp)
+RETURNING *;
+
+-- name: ClaimFeedsToFetch :many
+UPDATE feeds
+SET updated_at = sqlc.arg(now)::timestamp, claimed_until = sqlc.arg(claimed_until)::timestamp
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE disabled_at IS NULL
+    AND (next_fetch_at IS NULL OR next_fetch_at <= sqlc.arg(now)::timestamp)
+    AND (claimed_until IS NULL OR claimed_until <= sqlc.arg(now)::timestamp)
+    ORDER BY next_fetch_at ASC NULLS FIRST, last_fetched_at ASC NULLS FIRST
+    LIMIT sqlc.arg(max_feeds)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: UpdateFeedMetadata :exec
+UPDATE feeds
+SET updated_at = $2, site_title = $3, site_link = $4, description = $5, language = $6, image_url = $7, generator = $8, ttl = $9, skip_hours = $10, skip_days = $11
+WHERE $1 = id;
+
+-- name: SetFeedDownloadKeep :exec
+UPDATE feeds
+SET updated_at = $2, download_keep = $3
+WHERE $1 = id;
+
+-- name: UpdateFeedCacheHeaders :exec
+UPDATE feeds
+SET updated_at = $2, etag = $3, last_modified = $4
+WHERE $1 = id;
+
+-- name: RecordFeedSuccess :exec
+UPDATE feeds
+SET updated_at = $2, last_fetched_at = $2, last_success_at = $2, consecutive_failures = 0, last_error = NULL, next_fetch_at = $3, fetch_interval = $4, disabled_at = NULL, claimed_until = NULL
+WHERE $1 = id;
+
+-- name: RecordFeedFailure :exec
+UPDATE feeds
+SET updated_at = $2, last_fetched_at = $2, consecutive_failures = $3, last_error = $4, next_fetch_at = $5, disabled_at = $6, claimed_until = NULL
+WHERE $1 = id;
+
+-- name: EnableFeed :exec
 UPDATE feeds
-SET updated_at = $2, last_fetched_at = $2
+SET updated_at = $2, consecutive_failures = 0, last_error = NULL, next_fetch_at = NULL, disabled_at = NULL
 WHERE $1 = id;
 
--- name: GetNextFeedToFetch :one
-SELECT * FROM feeds ORDER BY last_fetched_at ASC NULLS FIRST LIMIT 1;
\ No newline at end of file
+-- name: ReleaseFeedClaim :exec
+UPDATE feeds
+SET updated_at = $2, claimed_until = NULL
+WHERE $1 = id;
\ No newline at end of file
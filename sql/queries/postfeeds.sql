@@ -0,0 +1,16 @@
+-- name: CreatePostFeeds :many
+INSERT INTO postfeeds (id, created_at, updated_at, post_id, feed_id, guid)
+SELECT
+    u.id,
+    sqlc.arg(created_at)::timestamp,
+    sqlc.arg(created_at)::timestamp,
+    u.post_id,
+    sqlc.arg(feed_id)::uuid,
+    u.guid
+FROM unnest(
+    sqlc.arg(ids)::uuid[],
+    sqlc.arg(post_ids)::uuid[],
+    sqlc.arg(guids)::text[]
+) AS u(id, post_id, guid)
+ON CONFLICT DO NOTHING
+RETURNING post_id;
\ No newline at end of file
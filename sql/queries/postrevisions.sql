@@ -0,0 +1,19 @@
+-- name: CreatePostRevisions :exec
+INSERT INTO postrevisions (id, created_at, post_id, title, description, content, content_hash)
+SELECT
+    u.id,
+    sqlc.arg(created_at)::timestamp,
+    posts.id,
+    posts.title,
+    posts.description,
+    posts.content,
+    posts.content_hash
+FROM unnest(
+    sqlc.arg(ids)::uuid[],
+    sqlc.arg(post_ids)::uuid[]
+) AS u(id, post_id)
+    INNER JOIN posts ON posts.id = u.post_id;
+
+-- name: GetPostRevisionCount :one
+SELECT count(*) FROM postrevisions
+WHERE post_id = $1;
\ No newline at end of file
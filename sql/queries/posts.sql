@@ -1,28 +1,91 @@
--- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES (
-    $1,
-    $2,
-    $3,
-    $4,
-    $5,
-    $6,
-    $7,
-    $8
-)
+-- name: CreatePosts :many
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, content, guid, content_hash)
+SELECT
+    u.id,
+    sqlc.arg(created_at)::timestamp,
+    sqlc.arg(created_at)::timestamp,
+    u.title,
+    u.url,
+    u.description,
+    u.published_at::timestamp,
+    sqlc.arg(feed_id)::uuid,
+    NULLIF(u.content, ''),
+    u.guid,
+    u.content_hash
+FROM unnest(
+    sqlc.arg(ids)::uuid[],
+    sqlc.arg(titles)::text[],
+    sqlc.arg(urls)::text[],
+    sqlc.arg(descriptions)::text[],
+    sqlc.arg(published_ats)::text[],
+    sqlc.arg(contents)::text[],
+    sqlc.arg(guids)::text[],
+    sqlc.arg(content_hashes)::text[]
+) AS u(id, title, url, description, published_at, content, guid, content_hash)
+ON CONFLICT (feed_id, guid) DO NOTHING
 RETURNING *;
 
+-- name: GetPostHashesForFeed :many
+SELECT postfeeds.guid, posts.id, posts.feed_id, posts.content_hash
+FROM postfeeds
+    INNER JOIN posts ON posts.id = postfeeds.post_id
+WHERE postfeeds.feed_id = sqlc.arg(feed_id) AND postfeeds.guid = ANY(sqlc.arg(guids)::text[]);
+
+-- name: GetPostsByURLs :many
+SELECT DISTINCT ON (url) * FROM posts
+WHERE url = ANY(sqlc.arg(urls)::text[])
+AND NOT EXISTS (
+    SELECT 1 FROM postfeeds
+    WHERE postfeeds.post_id = posts.id AND postfeeds.feed_id = sqlc.arg(feed_id)
+)
+ORDER BY url, created_at;
+
 -- name: GetPostsForUser :many
-SELECT
+SELECT DISTINCT ON (posts.published_at, posts.id)
     posts.*,
     feeds.name AS feed_name,
     users.name AS user_name
 FROM posts
-    INNER JOIN feedfollows ON posts.feed_id = feedfollows.feed_id
+    INNER JOIN postfeeds ON posts.id = postfeeds.post_id
+    INNER JOIN feedfollows ON postfeeds.feed_id = feedfollows.feed_id
     INNER JOIN users ON users.id = feedfollows.user_id
     INNER JOIN feeds ON feeds.id = feedfollows.feed_id
 WHERE
-    users.name = $1
+    users.name = sqlc.arg(name)
+AND (sqlc.narg(author)::text IS NULL OR EXISTS (
+    SELECT 1
+    FROM postauthors
+        INNER JOIN authors ON authors.id = postauthors.author_id
+    WHERE
+        postauthors.post_id = posts.id
+    AND lower(authors.name) = lower(sqlc.narg(author))
+))
+AND (sqlc.narg(category)::text IS NULL OR EXISTS (
+    SELECT 1
+    FROM postcategories
+        INNER JOIN categories ON categories.id = postcategories.category_id
+    WHERE
+        postcategories.post_id = posts.id
+    AND lower(categories.name) = lower(sqlc.narg(category))
+))
 ORDER BY
-    published_at DESC
-LIMIT $2;
+    posts.published_at DESC, posts.id, feeds.name
+LIMIT sqlc.arg('limit');
+
+-- name: UpdatePostsContent :exec
+UPDATE posts
+SET
+    updated_at = sqlc.arg(updated_at)::timestamp,
+    edited_at = sqlc.arg(updated_at)::timestamp,
+    title = u.title,
+    description = u.description,
+    content = NULLIF(u.content, ''),
+    content_hash = u.content_hash
+FROM unnest(
+    sqlc.arg(ids)::uuid[],
+    sqlc.arg(titles)::text[],
+    sqlc.arg(descriptions)::text[],
+    sqlc.arg(contents)::text[],
+    sqlc.arg(content_hashes)::text[]
+) AS u(id, title, description, content, content_hash)
+WHERE posts.id = u.id;
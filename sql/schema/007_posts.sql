@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE posts
+ADD guid TEXT;
+
+UPDATE posts SET guid = url;
+
+ALTER TABLE posts
+ALTER COLUMN guid SET NOT NULL,
+DROP CONSTRAINT posts_url_key,
+ADD CONSTRAINT UC_FeedGuid UNIQUE(feed_id,guid);
+
+-- +goose Down
+-- ALTER TABLE posts
+-- DROP CONSTRAINT UC_FeedGuid,
+-- ADD CONSTRAINT posts_url_key UNIQUE(url),
+-- DROP COLUMN guid;
\ No newline at end of file
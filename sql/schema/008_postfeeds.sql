@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE postfeeds (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    post_id UUID NOT NULL,
+    feed_id UUID NOT NULL,
+    guid TEXT NOT NULL,
+    CONSTRAINT UC_PostFeed UNIQUE(post_id,feed_id),
+    CONSTRAINT UC_PostFeedGuid UNIQUE(feed_id,guid),
+    FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+);
+
+INSERT INTO postfeeds (id, created_at, updated_at, post_id, feed_id, guid)
+SELECT gen_random_uuid(), created_at, updated_at, id, feed_id, guid FROM posts;
+
+CREATE INDEX posts_url_idx ON posts(url);
+
+-- +goose Down
+-- DROP INDEX posts_url_idx;
+-- DROP TABLE postfeeds;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE enclosures (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    post_id UUID NOT NULL,
+    url TEXT NOT NULL,
+    mime_type TEXT,
+    length BIGINT,
+    duration INTEGER,
+    episode INTEGER,
+    season INTEGER,
+    CONSTRAINT UC_PostEnclosure UNIQUE(post_id,url),
+    FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+-- DROP TABLE enclosures;
\ No newline at end of file
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE downloads (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    enclosure_id UUID UNIQUE NOT NULL,
+    feed_id UUID NOT NULL,
+    path TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    completed_at TIMESTAMP,
+    FOREIGN KEY(enclosure_id) REFERENCES enclosures(id) ON DELETE CASCADE,
+    FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+);
+
+ALTER TABLE feeds
+ADD download_keep INTEGER;
+
+-- +goose Down
+-- ALTER TABLE feeds
+-- DROP COLUMN download_keep;
+-- DROP TABLE downloads;
\ No newline at end of file
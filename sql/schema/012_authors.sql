@@ -0,0 +1,42 @@
+-- +goose Up
+CREATE TABLE authors (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    name TEXT UNIQUE NOT NULL
+);
+
+CREATE TABLE postauthors (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    post_id UUID NOT NULL,
+    author_id UUID NOT NULL,
+    CONSTRAINT UC_PostAuthor UNIQUE(post_id,author_id),
+    FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY(author_id) REFERENCES authors(id) ON DELETE CASCADE
+);
+
+CREATE TABLE categories (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    name TEXT UNIQUE NOT NULL
+);
+
+CREATE TABLE postcategories (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    post_id UUID NOT NULL,
+    category_id UUID NOT NULL,
+    CONSTRAINT UC_PostCategory UNIQUE(post_id,category_id),
+    FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY(category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+-- DROP TABLE postcategories;
+-- DROP TABLE categories;
+-- DROP TABLE postauthors;
+-- DROP TABLE authors;
\ No newline at end of file
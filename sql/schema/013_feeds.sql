@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD etag TEXT,
+ADD last_modified TEXT;
+
+-- +goose Down
+-- ALTER TABLE feeds
+-- DROP COLUMN etag,
+-- DROP COLUMN last_modified;
\ No newline at end of file
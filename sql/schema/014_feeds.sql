@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD consecutive_failures INTEGER NOT NULL DEFAULT 0,
+ADD last_error TEXT,
+ADD last_success_at TIMESTAMP,
+ADD next_fetch_at TIMESTAMP,
+ADD disabled_at TIMESTAMP;
+
+-- +goose Down
+-- ALTER TABLE feeds
+-- DROP COLUMN consecutive_failures,
+-- DROP COLUMN last_error,
+-- DROP COLUMN last_success_at,
+-- DROP COLUMN next_fetch_at,
+-- DROP COLUMN disabled_at;
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD ttl INTEGER,
+ADD skip_hours INTEGER[],
+ADD skip_days TEXT[];
+
+-- +goose Down
+-- ALTER TABLE feeds
+-- DROP COLUMN ttl,
+-- DROP COLUMN skip_hours,
+-- DROP COLUMN skip_days;
\ No newline at end of file
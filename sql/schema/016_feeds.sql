@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD fetch_interval INTEGER;
+
+-- +goose Down
+-- ALTER TABLE feeds
+-- DROP COLUMN fetch_interval;
\ No newline at end of file
@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE posts
+ADD content_hash TEXT,
+ADD edited_at TIMESTAMP;
+
+-- hash content of existing posts as postContentHash does
+UPDATE posts
+SET content_hash = encode(sha256(convert_to(concat_ws(E'\x1f', coalesce(title, ''), coalesce(description, ''), coalesce(content, '')), 'UTF8')), 'hex');
+
+CREATE TABLE postrevisions (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    post_id UUID NOT NULL,
+    title TEXT,
+    description TEXT,
+    content TEXT,
+    content_hash TEXT,
+    FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+-- DROP TABLE postrevisions;
+-- ALTER TABLE posts
+-- DROP COLUMN content_hash,
+-- DROP COLUMN edited_at;
\ No newline at end of file